@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
+	"slices"
+
+	"github.com/kopexa-grc/x/multierr"
 )
 
 var (
@@ -16,6 +18,12 @@ var (
 
 	// ErrInvalidSliceInput indicates the input cannot be converted to a slice
 	ErrInvalidSliceInput = errors.New("cannot convert to slice: must be slice or array")
+
+	// ErrUnsupportedType indicates a value that has no JSON representation
+	ErrUnsupportedType = errors.New("cannot convert to dict: unsupported type")
+
+	// ErrInvalidMapInput indicates the input cannot be converted from a dictionary
+	ErrInvalidMapInput = errors.New("cannot convert from dict: must be map with string keys")
 )
 
 // JSONToDict converts a Go value to a map[string]any representation.
@@ -27,34 +35,98 @@ var (
 // # Supported Input Types
 //
 //   - Structs and pointers to structs
-//   - map[string]any and map[string]interface{}
+//   - Maps with string, integer or encoding.TextMarshaler keys
+//   - Types implementing json.Marshaler that encode to a JSON object
+//   - Protobuf messages (proto.Message)
 //   - nil values
 //
 // # Conversion Rules
 //
-//   - Struct fields are converted using their json tag names when available
-//   - Unexported fields are ignored
-//   - Nested structs are recursively converted to maps
-//   - Slices of structs are converted to slices of maps
-//   - Primitive types and non-struct slices are preserved as-is
-//   - nil pointer structs return nil
+// The result has the same shape encoding/json would produce when marshaling
+// v and unmarshaling it into map[string]any, except that primitive values
+// keep their Go types (an int stays an int instead of becoming float64):
+//
+//   - Struct fields are named, omitted and promoted following encoding/json
+//   - Nested structs, maps of structs and slices of structs are converted
+//     to map[string]any and []any at any depth
+//   - Pointers are dereferenced; nil pointers, maps and slices become nil
+//   - Types implementing json.Marshaler are replaced by their decoded JSON,
+//     types implementing encoding.TextMarshaler by their text; time.Time
+//     therefore becomes an RFC 3339 string
+//   - Byte slices become base64 strings
+//   - Maps and slices of primitive values are preserved as-is
+//   - A map[string]any passed without options is returned as is, without
+//     converting its values or checking them for cycles; pass an option
+//     such as WithCyclePolicy(CycleError) to convert it like other maps.
+//     Dicts nested in other values are always converted
+//
+// # Protobuf Messages
+//
+// Protobuf messages, at the top level or nested in other values, are
+// converted through protoreflect following the protobuf JSON mapping rather
+// than by walking their generated Go fields:
+//
+//   - Fields use their JSON names (secret_id becomes "secretId") and unset
+//     fields are omitted
+//   - Enums become their value names, bytes become base64 strings
+//   - Well-known types such as google.protobuf.Timestamp, Duration, Struct
+//     and wrappers use their special JSON representation
+//   - Scalar fields keep their Go types, so int64 values stay int64 instead
+//     of becoming strings
 //
 // # JSON Tag Support
 //
-// The function respects json struct tags for field naming:
+// The function supports the json struct tag options of encoding/json:
 //
 //	type User struct {
-//		ID       int    `json:"user_id"`
-//		Name     string `json:"name"`
-//		Internal string `json:"-"`          // Ignored
-//		Public   string                     // Uses field name "Public"
+//		ID       int       `json:"user_id"`
+//		Name     string    `json:"name,omitempty"`    // Omitted if empty
+//		Created  time.Time `json:"created,omitzero"` // Omitted if zero
+//		Count    int64     `json:"count,string"`     // Encoded as "42"
+//		Internal string    `json:"-"`                // Ignored
+//		Public   string                              // Uses field name "Public"
+//		Base                                         // Fields promoted
+//		Meta     Metadata  `json:",inline"`          // Fields promoted
+//	}
+//
+// Fields of embedded structs without a tag name are promoted into the parent
+// and follow the encoding/json rules for name conflicts. The inline option,
+// which encoding/json does not support, promotes the fields of a named struct
+// field the same way.
+//
+// Fields tagged redact:"true" are replaced by DefaultRedactMask, so the
+// result can be written to logs and exports:
+//
+//	type Account struct {
+//		User     string `json:"user"`
+//		Password string `json:"password" redact:"true"` // "[REDACTED]"
 //	}
 //
+// # Options
+//
+// Values that refer back to one of their ancestors, such as parent pointers
+// in a tree, are detected and fail the conversion with ErrCycleDetected by
+// default. Values reached through several paths without forming a cycle are
+// converted every time they appear. The behavior is configured with opts:
+//
+//   - WithCyclePolicy(CycleMarker) replaces the repeated value with
+//     DefaultCycleMarker, WithCycleMarker with a custom value
+//   - WithCyclePolicy(CycleSkip) omits the repeated value
+//   - WithMaxDepth limits the nesting of dicts and slices in the result
+//   - WithInclude and WithExclude keep or drop values by path, such as
+//     "tokens[*].value"; maps and slices of plain values that are filtered
+//     are converted to map[string]any and []any
+//   - WithTransformer replaces the conversion of values of a type
+//   - WithRedactMask sets the mask for fields tagged redact:"true"
+//
 // # Performance
 //
-// This implementation is approximately 5x faster than JSON marshaling for simple
-// structs and 4x faster for complex nested structures, with significantly reduced
-// memory allocations.
+// The conversion plan of each type (field indexes, names and nested
+// converters) is compiled on first use and cached, so struct tags are parsed
+// only once per type. This implementation is approximately 12-13x faster
+// than JSON marshaling for simple and nested structs, with 73-76% less
+// memory usage and a fraction of the allocations, see the package
+// documentation.
 //
 // # Parameters
 //
@@ -70,6 +142,14 @@ var (
 // Returns an error if:
 //   - The input is not a struct, map, or nil
 //   - The input is a non-nil pointer to a non-struct type
+//   - A value has no JSON representation, such as a channel or function
+//     (ErrUnsupportedType), or a custom marshaler fails
+//   - A value refers back to one of its ancestors under CycleError
+//     (ErrCycleDetected)
+//   - The result is nested deeper than WithMaxDepth allows
+//     (ErrMaxDepthExceeded)
+//   - A path given to WithInclude or WithExclude is invalid (ErrInvalidPath)
+//   - A transformer fails
 //
 // # Examples
 //
@@ -103,102 +183,62 @@ var (
 //	//   "name": "Bob",
 //	//   "address": map[string]any{"street": "123 Main St", "city": "NYC"}
 //	// }
-func JSONToDict(v any) (map[string]any, error) {
+//
+//	// Tree with parent pointers
+//	type Node struct {
+//		Name     string  `json:"name"`
+//		Parent   *Node   `json:"parent,omitempty"`
+//		Children []*Node `json:"children,omitempty"`
+//	}
+//
+//	root := &Node{Name: "root"}
+//	root.Children = []*Node{{Name: "a", Parent: root}}
+//	result, err := JSONToDict(root, WithCyclePolicy(CycleMarker))
+//	// result: map[string]any{
+//	//   "name": "root",
+//	//   "children": []any{map[string]any{"name": "a", "parent": "[circular]"}}
+//	// }
+func JSONToDict(v any, opts ...DictOption) (map[string]any, error) {
 	if v == nil {
 		return nil, nil
 	}
 
 	// If it's already a map[string]any, return it directly
-	if dict, ok := v.(map[string]any); ok {
+	if dict, ok := v.(map[string]any); ok && len(opts) == 0 {
 		return dict, nil
 	}
 
-	// If it's a map[string]interface{}, convert it
-	if dict, ok := v.(map[string]interface{}); ok {
-		result := make(map[string]any)
-		for k, val := range dict {
-			result[k] = val
-		}
-		return result, nil
-	}
+	return encodeDict(v, opts)
+}
 
-	// Use reflection for struct conversion
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return nil, nil
-		}
-		rv = rv.Elem()
-	}
+// encodeDict converts v with the encoder. It is separate from JSONToDict so
+// that the fast path for dicts stays cheap.
+func encodeDict(v any, opts []DictOption) (map[string]any, error) {
+	e := newEncodeState(newDictOptions(opts))
+	defer e.release()
 
-	if rv.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("%w: got %T", ErrInvalidDictInput, v)
+	if e.err != nil {
+		return nil, e.err
 	}
 
-	result := make(map[string]any)
-	rt := rv.Type()
-
-	for i := 0; i < rv.NumField(); i++ {
-		field := rt.Field(i)
-		if !field.IsExported() {
-			continue
-		}
-
-		fieldValue := rv.Field(i)
-		if !fieldValue.CanInterface() {
-			continue
-		}
-
-		// Use json tag if available, otherwise use field name
-		name := field.Name
-		if tag := field.Tag.Get("json"); tag != "" && tag != "-" {
-			// Extract field name from json tag (before comma if present)
-			if commaIdx := strings.Index(tag, ","); commaIdx != -1 {
-				name = tag[:commaIdx]
-			} else {
-				name = tag
-			}
-		}
+	out, err := encodeValue(e, reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
 
-		value := fieldValue.Interface()
+	switch dict := out.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return dict, nil
+	}
 
-		// Recursively convert nested structs to match JSON behavior
-		switch fieldValue.Kind() {
-		case reflect.Struct:
-			if nestedDict, err := JSONToDict(value); err == nil {
-				result[name] = nestedDict
-			} else {
-				result[name] = value
-			}
-		case reflect.Slice, reflect.Array:
-			// Handle slices of structs
-			sliceLen := fieldValue.Len()
-			if sliceLen > 0 {
-				// Check if it's a slice of structs
-				firstElem := fieldValue.Index(0)
-				if firstElem.Kind() == reflect.Struct {
-					convertedSlice := make([]any, sliceLen)
-					for j := 0; j < sliceLen; j++ {
-						elem := fieldValue.Index(j).Interface()
-						if elemDict, err := JSONToDict(elem); err == nil {
-							convertedSlice[j] = elemDict
-						} else {
-							convertedSlice[j] = elem
-						}
-					}
-					result[name] = convertedSlice
-				} else {
-					result[name] = value
-				}
-			} else {
-				result[name] = value
-			}
-		default:
-			result[name] = value
-		}
+	// maps of plain values are preserved by encodeValue
+	if rv := reflect.ValueOf(out); rv.Kind() == reflect.Map {
+		return encodeMap(e, rv)
 	}
 
-	return result, nil
+	return nil, fmt.Errorf("%w: got %T", ErrInvalidDictInput, v)
 }
 
 // JSONToDictSlice converts a slice or array to a slice of map[string]any.
@@ -215,15 +255,20 @@ func JSONToDict(v any) (map[string]any, error) {
 //
 // # Conversion Rules
 //
-//   - Each slice element is processed through JSONToDict if it's a struct
-//   - Non-struct elements are preserved as-is
+//   - Each slice element is converted using the rules of JSONToDict, so
+//     structs become map[string]any
+//   - Primitive elements are preserved as-is
 //   - Empty slices return empty slices
 //   - nil slices return nil
+//   - opts apply as for JSONToDict, with the returned slice as the first
+//     level for WithMaxDepth
 //
 // # Performance
 //
-// This implementation is approximately 4x faster than JSON marshaling for slices
+// This implementation is approximately 10x faster than JSON marshaling for slices
 // of structs, with 73% less memory usage and significantly fewer allocations.
+// For large inputs, JSONToDictSeq and EncodeDictSlice convert elements one at
+// a time without building the result slice.
 //
 // # Parameters
 //
@@ -239,6 +284,7 @@ func JSONToDict(v any) (map[string]any, error) {
 // Returns an error if:
 //   - The input is not a slice, array, or pointer to slice/array
 //   - The input is a non-nil pointer to a non-slice type
+//   - An element cannot be converted, see JSONToDict
 //
 // # Examples
 //
@@ -278,36 +324,42 @@ func JSONToDict(v any) (map[string]any, error) {
 //	//   "simple string",
 //	//   42
 //	// }
-func JSONToDictSlice(v any) ([]any, error) {
+func JSONToDictSlice(v any, opts ...DictOption) ([]any, error) {
+	rv, err := sliceValue(v)
+	if err != nil || !rv.IsValid() {
+		return nil, err
+	}
+
+	e := newEncodeState(newDictOptions(opts))
+	defer e.release()
+
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return newElemsEncoder(rv.Type())(e, rv)
+}
+
+// sliceValue returns the slice or array v, dereferencing a pointer. It
+// returns the zero Value for nil.
+func sliceValue(v any) (reflect.Value, error) {
 	if v == nil {
-		return nil, nil
+		return reflect.Value{}, nil
 	}
 
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
-			return nil, nil
+			return reflect.Value{}, nil
 		}
 		rv = rv.Elem()
 	}
 
 	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
-		return nil, fmt.Errorf("%w: got %T", ErrInvalidSliceInput, v)
-	}
-
-	result := make([]any, rv.Len())
-	for i := 0; i < rv.Len(); i++ {
-		item := rv.Index(i).Interface()
-		// Convert each item to a dict if it's a struct
-		if dict, err := JSONToDict(item); err == nil {
-			result[i] = dict
-		} else {
-			// If conversion fails, use the item as-is
-			result[i] = item
-		}
+		return reflect.Value{}, fmt.Errorf("%w: got %T", ErrInvalidSliceInput, v)
 	}
 
-	return result, nil
+	return rv, nil
 }
 
 // DictToTypedMap safely converts a dictionary to a strongly-typed map[string]T.
@@ -316,6 +368,9 @@ func JSONToDictSlice(v any) ([]any, error) {
 // extracts only the values that can be type-asserted to the specified type T.
 // Values that cannot be converted to T are silently discarded.
 //
+// Use DictToTypedMapStrict or DictToTypedMapCoerce to convert values, such as
+// float64 numbers decoded from JSON into int, and to learn which keys failed.
+//
 // # Type Safety
 //
 // The function uses Go's type assertion to safely check each value's type at runtime.
@@ -394,3 +449,102 @@ func DictToTypedMap[T any](d any) map[string]T {
 	}
 	return m
 }
+
+// DictToTypedMapStrict converts the values of a dictionary into a
+// map[string]T, reporting values that cannot be converted.
+//
+// Values are converted with the rules of DictToStruct: numbers, including
+// json.Number, are converted between numeric types if the value fits
+// exactly, nested dicts are converted into structs and maps, and slices are
+// converted element-wise. nil values become the zero value of T. Strings
+// are not parsed into numbers or booleans; use DictToTypedMapCoerce for
+// that.
+//
+// # Supported Input Types
+//
+//   - map[string]any and other maps with string keys, such as
+//     map[string]string
+//   - nil (returns nil)
+//
+// # Errors
+//
+// Returns ErrInvalidMapInput if d is not a map with string keys. If values
+// fail to convert, the returned map holds all other values and the error is
+// a *multierr.Errors with one *FieldError per failed key, ordered by key.
+//
+// # Examples
+//
+//	var data map[string]any
+//	_ = json.Unmarshal([]byte(`{"replicas": 3, "retries": 2.5, "name": "api"}`), &data)
+//
+//	counts, err := DictToTypedMapStrict[int](data)
+//	// counts: map[string]int{"replicas": 3}
+//	// err: 2 errors occurred:
+//	//   * name: cannot convert to int: type mismatch: got string
+//	//   * retries: cannot convert to int: number is not an integer
+func DictToTypedMapStrict[T any](d any) (map[string]T, error) {
+	return decodeTypedMap[T](decoder{}, d)
+}
+
+// DictToTypedMapCoerce converts the values of a dictionary into a
+// map[string]T like DictToTypedMapStrict, additionally coercing scalars:
+//
+//   - Strings are parsed into numbers ("42", "1.5") and booleans ("true",
+//     "0"); surrounding whitespace is ignored
+//   - Numbers and booleans are formatted into strings (42 becomes "42")
+//
+// Coercion applies at any depth, so nested dicts are converted into structs
+// and maps with coerced fields.
+//
+// # Errors
+//
+// Returns ErrInvalidMapInput if d is not a map with string keys. If values
+// fail to convert, the returned map holds all other values and the error is
+// a *multierr.Errors with one *FieldError per failed key, ordered by key.
+//
+// # Examples
+//
+//	env := map[string]any{"PORT": "8080", "WORKERS": 4, "DEBUG": "yes"}
+//
+//	ports, err := DictToTypedMapCoerce[int](env)
+//	// ports: map[string]int{"PORT": 8080, "WORKERS": 4}
+//	// err: 1 error occurred:
+//	//   * DEBUG: cannot convert to int: type mismatch: "yes" is not a number
+func DictToTypedMapCoerce[T any](d any) (map[string]T, error) {
+	return decodeTypedMap[T](decoder{coerce: true}, d)
+}
+
+func decodeTypedMap[T any](dec decoder, d any) (map[string]T, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	dict, ok := asDict(d)
+	if !ok {
+		return nil, fmt.Errorf("%w: got %T", ErrInvalidMapInput, d)
+	}
+
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	result := make(map[string]T, len(dict))
+
+	var errs multierr.Errors
+	for _, k := range keys {
+		var v T
+		if err := dec.decodeValue(k, dict[k], reflect.ValueOf(&v).Elem()); err != nil {
+			errs.Add(err)
+			continue
+		}
+		result[k] = v
+	}
+
+	if !errs.IsEmpty() {
+		return result, &errs
+	}
+
+	return result, nil
+}
@@ -4,10 +4,19 @@
 package convert_test
 
 import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/kopexa-grc/x/convert"
+	"github.com/kopexa-grc/x/multierr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test JSONToDict function
@@ -44,6 +53,333 @@ func TestJSONToDict_Nil(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+type shapeLevel string
+
+func (l shapeLevel) MarshalText() ([]byte, error) { return []byte(strings.ToUpper(string(l))), nil }
+
+type shapePoint struct{ X, Y int }
+
+func (p *shapePoint) MarshalJSON() ([]byte, error) { return json.Marshal([]int{p.X, p.Y}) }
+
+type shapeMoney struct{ Cents int64 }
+
+func (m shapeMoney) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"amount": float64(m.Cents) / 100, "currency": "EUR"})
+}
+
+type shapeBase struct {
+	ID      string `json:"id"`
+	Version int    `json:"version,omitempty"`
+	Shared  string
+}
+
+type shapeAudit struct {
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	Shared    string
+}
+
+type shapeMeta struct {
+	Owner string `json:"owner"`
+}
+
+type shapeItem struct {
+	Name  string      `json:"name"`
+	Level shapeLevel  `json:"level"`
+	Price *shapeMoney `json:"price,omitempty"`
+}
+
+type shapeDoc struct {
+	shapeBase
+	*shapeAudit
+	Meta      *shapeMeta             `json:"meta"`
+	Dash      string                 `json:"-,"`
+	Skipped   string                 `json:"-"`
+	Count     int64                  `json:"count,string"`
+	Ratio     *float64               `json:"ratio,string"`
+	Label     string                 `json:"label,string"`
+	Empty     []string               `json:"empty,omitempty"`
+	Zero      time.Time              `json:"zero,omitzero"`
+	Items     []shapeItem            `json:"items"`
+	ItemPtrs  []*shapeItem           `json:"item_ptrs"`
+	ByName    map[string]shapeItem   `json:"by_name"`
+	ByID      map[int]string         `json:"by_id"`
+	ByLevel   map[shapeLevel]int     `json:"by_level"`
+	Points    []shapePoint           `json:"points"`
+	Point     shapePoint             `json:"point"`
+	Price     shapeMoney             `json:"price"`
+	IP        net.IP                 `json:"ip"`
+	Raw       []byte                 `json:"raw"`
+	Matrix    [2][]shapeItem         `json:"matrix"`
+	Mixed     []any                  `json:"mixed"`
+	Extra     map[string]any         `json:"extra"`
+	Nothing   map[string]string      `json:"nothing"`
+	Interface any                    `json:"interface"`
+	Nested    map[string][]shapeItem `json:"nested"`
+	private   string
+}
+
+func newShapeDoc() shapeDoc {
+	ratio := 0.25
+	item := shapeItem{Name: "a", Level: "low", Price: &shapeMoney{Cents: 1999}}
+
+	return shapeDoc{
+		shapeBase:  shapeBase{ID: "doc-1", Shared: "ambiguous"},
+		shapeAudit: &shapeAudit{CreatedBy: "alice", CreatedAt: time.Date(2025, 8, 6, 10, 0, 0, 0, time.UTC), Shared: "ambiguous"},
+		Meta:       &shapeMeta{Owner: "bob"},
+		Dash:       "dash",
+		Skipped:    "skipped",
+		Count:      42,
+		Ratio:      &ratio,
+		Label:      "<b>",
+		Zero:       time.Time{},
+		Items:      []shapeItem{item, {Name: "b"}},
+		ItemPtrs:   []*shapeItem{nil, &item},
+		ByName:     map[string]shapeItem{"a": item},
+		ByID:       map[int]string{1: "one", -2: "minus two"},
+		ByLevel:    map[shapeLevel]int{"high": 3},
+		Points:     []shapePoint{{X: 1, Y: 2}},
+		Point:      shapePoint{X: 3, Y: 4},
+		Price:      shapeMoney{Cents: 500},
+		IP:         net.ParseIP("192.0.2.1"),
+		Raw:        []byte("raw"),
+		Matrix:     [2][]shapeItem{{item}, nil},
+		Mixed:      []any{1, "two", item, &item, []shapeItem{item}},
+		Extra:      map[string]any{"item": item, "n": 1},
+		Interface:  item,
+		Nested:     map[string][]shapeItem{"x": {item}},
+		private:    "private",
+	}
+}
+
+// assertSameShape checks JSONToDict against json.Marshal followed by
+// json.Unmarshal into map[string]any.
+func assertSameShape(t *testing.T, v any) map[string]any {
+	t.Helper()
+
+	want, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	dict, err := convert.JSONToDict(v)
+	require.NoError(t, err)
+	assertNoStructs(t, "", reflect.ValueOf(dict))
+
+	got, err := json.Marshal(dict)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+
+	return dict
+}
+
+// assertNoStructs fails if a struct or pointer survived the conversion.
+func assertNoStructs(t *testing.T, path string, v reflect.Value) {
+	t.Helper()
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			assertNoStructs(t, path, v.Elem())
+		}
+	case reflect.Struct, reflect.Ptr:
+		t.Errorf("%s: unconverted %s", path, v.Type())
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			assertNoStructs(t, path+"."+iter.Key().String(), iter.Value())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			assertNoStructs(t, path+"[]", v.Index(i))
+		}
+	}
+}
+
+func TestJSONToDict_MatchesEncodingJSON(t *testing.T) {
+	doc := newShapeDoc()
+
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{name: "struct", input: doc},
+		{name: "pointer", input: &doc},
+		{name: "nil embedded pointer", input: shapeDoc{shapeBase: shapeBase{ID: "x", Version: 2}}},
+		{name: "map of structs", input: map[string]shapeItem{"a": doc.Items[0]}},
+		{name: "map with int keys", input: map[int]shapeItem{7: doc.Items[1]}},
+		{name: "marshaler object", input: doc.Price},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSameShape(t, tt.input)
+		})
+	}
+}
+
+func TestJSONToDict_TagOptions(t *testing.T) {
+	dict := assertSameShape(t, newShapeDoc())
+
+	assert.Equal(t, "doc-1", dict["id"], "embedded fields are promoted")
+	assert.NotContains(t, dict, "version", "omitempty")
+	assert.NotContains(t, dict, "Shared", "conflicting fields at the same depth are dropped")
+	assert.Equal(t, "alice", dict["created_by"], "embedded pointer fields are promoted")
+	assert.Equal(t, "2025-08-06T10:00:00Z", dict["created_at"], "time.Time uses MarshalJSON")
+	assert.Equal(t, "dash", dict["-"])
+	assert.NotContains(t, dict, "Skipped")
+	assert.Equal(t, "42", dict["count"])
+	assert.Equal(t, "0.25", dict["ratio"])
+	assert.Equal(t, `"\u003cb\u003e"`, dict["label"])
+	assert.NotContains(t, dict, "empty")
+	assert.NotContains(t, dict, "zero", "omitzero")
+	assert.NotContains(t, dict, "private")
+	assert.Equal(t, map[string]any{"1": "one", "-2": "minus two"}, dict["by_id"])
+	assert.Equal(t, map[shapeLevel]int{"high": 3}, dict["by_level"], "string keys win over MarshalText")
+	assert.Equal(t, []any{float64(1), float64(2)}, dict["points"].([]any)[0], "pointer receiver marshaler on addressable element")
+	assert.Equal(t, "192.0.2.1", dict["ip"])
+	assert.Nil(t, dict["item_ptrs"].([]any)[0])
+
+	items := dict["items"].([]any)
+	assert.Equal(t, map[string]any{"name": "b", "level": ""}, items[1])
+	assert.Equal(t, 1, dict["extra"].(map[string]any)["n"], "primitive types are preserved")
+}
+
+func TestJSONToDict_DominantField(t *testing.T) {
+	type inner struct {
+		Name  string `json:"name"`
+		Value int
+	}
+	type outer struct {
+		inner
+		Name string
+	}
+	type tagged struct {
+		inner
+		Label string `json:"Value"`
+	}
+
+	dict := assertSameShape(t, outer{inner: inner{Name: "inner", Value: 1}, Name: "outer"})
+	assert.Equal(t, map[string]any{"name": "inner", "Value": 1, "Name": "outer"}, dict)
+
+	dict = assertSameShape(t, tagged{inner: inner{Value: 1}, Label: "tagged"})
+	assert.Equal(t, "tagged", dict["Value"])
+}
+
+func TestJSONToDict_Inline(t *testing.T) {
+	type meta struct {
+		Owner string `json:"owner"`
+	}
+	type doc struct {
+		Name string `json:"name"`
+		Meta meta   `json:",inline"`
+	}
+
+	dict, err := convert.JSONToDict(doc{Name: "n", Meta: meta{Owner: "o"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "n", "owner": "o"}, dict)
+}
+
+func TestJSONToDict_PreservesPlainValues(t *testing.T) {
+	input := map[string]any{"tags": []any{"a", 1}, "nested": map[string]any{"ok": true}}
+
+	result, err := convert.JSONToDict(input)
+	require.NoError(t, err)
+	assert.Equal(t, reflect.ValueOf(input).Pointer(), reflect.ValueOf(result).Pointer(), "plain dicts are returned as is")
+
+	type plain struct {
+		Tags []string          `json:"tags"`
+		Env  map[string]string `json:"env"`
+	}
+
+	result, err = convert.JSONToDict(plain{Tags: []string{"a"}, Env: map[string]string{"k": "v"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, result["tags"])
+	assert.Equal(t, map[string]string{"k": "v"}, result["env"])
+}
+
+func TestJSONToDict_Bytes(t *testing.T) {
+	type doc struct {
+		Raw    []byte            `json:"raw"`
+		Nil    []byte            `json:"nil"`
+		Chunks [][]byte          `json:"chunks"`
+		ByName map[string][]byte `json:"by_name"`
+		Fixed  [2]byte           `json:"fixed"`
+		Mixed  []any             `json:"mixed"`
+	}
+
+	dict := assertSameShape(t, doc{
+		Raw:    []byte("raw"),
+		Chunks: [][]byte{[]byte("a")},
+		ByName: map[string][]byte{"b": []byte("b")},
+		Fixed:  [2]byte{1, 2},
+		Mixed:  []any{[]byte("c")},
+	})
+
+	assert.Equal(t, "cmF3", dict["raw"])
+	assert.Nil(t, dict["nil"])
+	assert.Equal(t, []any{"YQ=="}, dict["chunks"])
+	assert.Equal(t, map[string]any{"b": "Yg=="}, dict["by_name"])
+	assert.Equal(t, [2]byte{1, 2}, dict["fixed"], "byte arrays are encoded as arrays, as encoding/json does")
+	assert.Equal(t, []any{"Yw=="}, dict["mixed"])
+}
+
+func TestJSONToDict_Errors(t *testing.T) {
+	_, err := convert.JSONToDict(struct {
+		Ch chan int `json:"ch"`
+	}{})
+	assert.ErrorIs(t, err, convert.ErrUnsupportedType)
+	assert.Contains(t, err.Error(), "ch")
+
+	_, err = convert.JSONToDict(42)
+	assert.ErrorIs(t, err, convert.ErrInvalidDictInput)
+
+	_, err = convert.JSONToDict(struct {
+		Bad failingMarshaler `json:"bad"`
+	}{})
+	assert.ErrorIs(t, err, errMarshal)
+}
+
+var errMarshal = errors.New("marshal failed")
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errMarshal }
+
+type shapeNode struct {
+	Name     string      `json:"name"`
+	Children []shapeNode `json:"children,omitempty"`
+	Parent   *shapeNode  `json:"parent,omitempty"`
+}
+
+func TestJSONToDict_RecursiveType(t *testing.T) {
+	root := shapeNode{Name: "root", Children: []shapeNode{
+		{Name: "a", Children: []shapeNode{{Name: "a1"}}},
+		{Name: "b", Parent: &shapeNode{Name: "root"}},
+	}}
+
+	assertSameShape(t, root)
+}
+
+func TestJSONToDict_Concurrent(t *testing.T) {
+	doc := newShapeDoc()
+
+	want, err := convert.JSONToDict(doc)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				got, err := convert.JSONToDict(doc)
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 // Test JSONToDictSlice function
 func TestJSONToDictSlice(t *testing.T) {
 	type TestStruct struct {
@@ -131,3 +467,161 @@ func TestDictToMapStr_NonMapInput(t *testing.T) {
 	output := convert.DictToTypedMap[int]("not a map")
 	assert.Empty(t, output)
 }
+
+// fieldErrors returns the paths and reasons of the field errors in err.
+func fieldErrors(t *testing.T, err error) map[string]error {
+	t.Helper()
+
+	var errs *multierr.Errors
+	require.ErrorAs(t, err, &errs)
+
+	result := make(map[string]error, len(errs.Errors))
+	for _, e := range errs.Errors {
+		var fieldErr *convert.FieldError
+		require.ErrorAs(t, e, &fieldErr)
+		result[fieldErr.Path] = fieldErr.Err
+	}
+
+	return result
+}
+
+func TestDictToTypedMapStrict(t *testing.T) {
+	var data map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"replicas": 3,
+		"retries": 2.5,
+		"name": "api",
+		"port": "8080",
+		"big": 1e30,
+		"unset": null
+	}`), &data))
+
+	counts, err := convert.DictToTypedMapStrict[int](data)
+	assert.Equal(t, map[string]int{"replicas": 3, "unset": 0}, counts)
+
+	failed := fieldErrors(t, err)
+	assert.Len(t, failed, 4)
+	assert.ErrorIs(t, failed["retries"], convert.ErrNotInteger)
+	assert.ErrorIs(t, failed["big"], convert.ErrNumberOverflow)
+	assert.ErrorIs(t, failed["name"], convert.ErrTypeMismatch)
+	assert.ErrorIs(t, failed["port"], convert.ErrTypeMismatch)
+	assert.Contains(t, err.Error(), "4 errors occurred")
+}
+
+func TestDictToTypedMapStrict_Nested(t *testing.T) {
+	type limits struct {
+		CPU    float64 `json:"cpu"`
+		Memory int     `json:"memory"`
+	}
+
+	data := map[string]any{
+		"api":    map[string]any{"cpu": 0.5, "memory": float64(512)},
+		"worker": map[string]any{"cpu": 1, "memory": "lots"},
+		"db":     json.Number("1"),
+	}
+
+	result, err := convert.DictToTypedMapStrict[limits](data)
+	assert.Equal(t, map[string]limits{"api": {CPU: 0.5, Memory: 512}}, result)
+
+	failed := fieldErrors(t, err)
+	assert.Len(t, failed, 2)
+	assert.ErrorIs(t, failed["db"], convert.ErrTypeMismatch)
+	assert.ErrorIs(t, failed["worker.memory"], convert.ErrTypeMismatch)
+
+	labels, err := convert.DictToTypedMapStrict[map[string]string](map[string]map[string]any{
+		"api": {"tier": "frontend"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"api": {"tier": "frontend"}}, labels)
+}
+
+func TestDictToTypedMapStrict_Input(t *testing.T) {
+	result, err := convert.DictToTypedMapStrict[int](nil)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	_, err = convert.DictToTypedMapStrict[int]("not a map")
+	assert.ErrorIs(t, err, convert.ErrInvalidMapInput)
+
+	_, err = convert.DictToTypedMapStrict[int](map[int]any{1: 1})
+	assert.ErrorIs(t, err, convert.ErrInvalidMapInput)
+
+	typed, err := convert.DictToTypedMapStrict[int64](map[string]int{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1}, typed)
+}
+
+func TestDictToTypedMapCoerce(t *testing.T) {
+	t.Run("numbers", func(t *testing.T) {
+		result, err := convert.DictToTypedMapCoerce[int](map[string]any{
+			"port":    " 8080 ",
+			"workers": float64(4),
+			"count":   json.Number("7"),
+			"ratio":   "2.0",
+			"half":    "2.5",
+			"debug":   "yes",
+		})
+		assert.Equal(t, map[string]int{"port": 8080, "workers": 4, "count": 7, "ratio": 2}, result)
+
+		failed := fieldErrors(t, err)
+		assert.Len(t, failed, 2)
+		assert.ErrorIs(t, failed["half"], convert.ErrNotInteger)
+		assert.ErrorIs(t, failed["debug"], convert.ErrTypeMismatch)
+		assert.ErrorContains(t, failed["debug"], `"yes" is not a number`)
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		result, err := convert.DictToTypedMapCoerce[string](map[string]any{
+			"int":    42,
+			"float":  85.5,
+			"large":  float64(1e21),
+			"bool":   true,
+			"number": json.Number("1e3"),
+			"text":   "ok",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"int":    "42",
+			"float":  "85.5",
+			"large":  "1000000000000000000000",
+			"bool":   "true",
+			"number": "1e3",
+			"text":   "ok",
+		}, result)
+
+		_, err = convert.DictToTypedMapCoerce[string](map[string]any{"list": []any{"a"}})
+		assert.ErrorIs(t, fieldErrors(t, err)["list"], convert.ErrTypeMismatch)
+	})
+
+	t.Run("booleans", func(t *testing.T) {
+		result, err := convert.DictToTypedMapCoerce[bool](map[string]any{"a": "true", "b": "0", "c": false, "d": "maybe"})
+		assert.Equal(t, map[string]bool{"a": true, "b": false, "c": false}, result)
+		assert.ErrorContains(t, fieldErrors(t, err)["d"], `"maybe" is not a boolean`)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		type service struct {
+			Port    uint16             `json:"port"`
+			Enabled bool               `json:"enabled"`
+			Tags    []string           `json:"tags"`
+			Limits  map[string]float64 `json:"limits"`
+		}
+
+		result, err := convert.DictToTypedMapCoerce[service](map[string]any{
+			"api": map[string]any{
+				"port":    "443",
+				"enabled": "true",
+				"tags":    []any{1, "edge"},
+				"limits":  map[string]any{"cpu": "0.5"},
+			},
+			"bad": map[string]any{"port": "70000"},
+		})
+		assert.Equal(t, map[string]service{"api": {
+			Port:    443,
+			Enabled: true,
+			Tags:    []string{"1", "edge"},
+			Limits:  map[string]float64{"cpu": 0.5},
+		}}, result)
+		assert.ErrorIs(t, fieldErrors(t, err)["bad.port"], convert.ErrNumberOverflow)
+	})
+}
@@ -0,0 +1,281 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// ChangeType describes the kind of a Change.
+type ChangeType string
+
+const (
+	// ChangeAdd indicates a value that exists only in the new dict.
+	ChangeAdd ChangeType = "add"
+
+	// ChangeRemove indicates a value that exists only in the old dict.
+	ChangeRemove ChangeType = "remove"
+
+	// ChangeReplace indicates a value that differs between both dicts.
+	ChangeReplace ChangeType = "replace"
+)
+
+// Change describes a single difference between two dicts.
+type Change struct {
+	// Type is the kind of change.
+	Type ChangeType `json:"type"`
+
+	// Path is the location of the change, usable with Get.
+	Path string `json:"path"`
+
+	// From is the old value. It is nil for ChangeAdd.
+	From any `json:"from,omitempty"`
+
+	// To is the new value. It is nil for ChangeRemove.
+	To any `json:"to,omitempty"`
+}
+
+// Diff returns the structural differences between from and to.
+//
+// Nested dicts are compared key by key and slices index by index, so a
+// change deep inside a document is reported at its own path rather than as
+// a replacement of the enclosing value. Numbers are compared by value, so
+// int(2) and float64(2) are equal.
+//
+// Changes are ordered by path, with removals of slice elements ordered from
+// the highest index down, so they can be applied in order. CreatePatch
+// returns the same changes as an RFC 6902 JSON Patch.
+//
+// # Examples
+//
+//	from := map[string]any{"control": map[string]any{"status": "draft", "owners": []any{"alice"}}}
+//	to := map[string]any{"control": map[string]any{"status": "approved", "owners": []any{"alice", "bob"}}}
+//
+//	changes := Diff(from, to)
+//	// changes: []Change{
+//	//   {Type: ChangeAdd, Path: "control.owners[1]", To: "bob"},
+//	//   {Type: ChangeReplace, Path: "control.status", From: "draft", To: "approved"},
+//	// }
+func Diff(from, to map[string]any) []Change {
+	var changes []Change
+
+	diffValues(nil, from, to, func(t ChangeType, segs []pathSegment, from, to any) {
+		changes = append(changes, Change{Type: t, Path: formatPath(segs), From: cloneValue(from), To: cloneValue(to)})
+	})
+
+	return changes
+}
+
+// diffFunc receives each difference found by diffValues.
+type diffFunc func(t ChangeType, segs []pathSegment, from, to any)
+
+// diffValues reports the differences between from and to at segs.
+func diffValues(segs []pathSegment, from, to any, report diffFunc) {
+	if fd, ok := asDict(from); ok {
+		if td, ok := asDict(to); ok {
+			diffDicts(segs, fd, td, report)
+			return
+		}
+	}
+
+	if fs, ok := asSlice(from); ok {
+		if ts, ok := asSlice(to); ok {
+			diffSlices(segs, fs, ts, report)
+			return
+		}
+	}
+
+	if !equalValues(from, to) {
+		report(ChangeReplace, segs, from, to)
+	}
+}
+
+func diffDicts(segs []pathSegment, from, to map[string]any, report diffFunc) {
+	keys := make([]string, 0, len(from)+len(to))
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		child := append(slices.Clip(segs), pathSegment{key: k})
+
+		fv, inFrom := from[k]
+		tv, inTo := to[k]
+
+		switch {
+		case !inTo:
+			report(ChangeRemove, child, fv, nil)
+		case !inFrom:
+			report(ChangeAdd, child, nil, tv)
+		default:
+			diffValues(child, fv, tv, report)
+		}
+	}
+}
+
+func diffSlices(segs []pathSegment, from, to []any, report diffFunc) {
+	common := min(len(from), len(to))
+
+	for i := 0; i < common; i++ {
+		diffValues(append(slices.Clip(segs), pathSegment{index: i, isIndex: true}), from[i], to[i], report)
+	}
+
+	for i := len(from) - 1; i >= common; i-- {
+		report(ChangeRemove, append(slices.Clip(segs), pathSegment{index: i, isIndex: true}), from[i], nil)
+	}
+
+	for i := common; i < len(to); i++ {
+		report(ChangeAdd, append(slices.Clip(segs), pathSegment{index: i, isIndex: true}), nil, to[i])
+	}
+}
+
+// Equal reports whether a and b are structurally equal. Dicts and slices are
+// compared deeply, typed maps and slices are compared with their
+// map[string]any and []any counterparts and numbers are compared by value:
+// integers of any type, including json.Number, are compared exactly, and
+// only non-integral floats are compared as float64.
+func Equal(a, b any) bool {
+	return equalValues(a, b)
+}
+
+func equalValues(a, b any) bool {
+	if ad, ok := asDict(a); ok {
+		bd, ok := asDict(b)
+		if !ok || len(ad) != len(bd) {
+			return false
+		}
+		for k, av := range ad {
+			bv, ok := bd[k]
+			if !ok || !equalValues(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if as, ok := asSlice(a); ok {
+		bs, ok := asSlice(b)
+		if !ok || len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if !equalValues(as[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if an, ok := numberOf(a); ok {
+		bn, ok := numberOf(b)
+		return ok && an.equal(bn)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// number is a JSON number. Integers are kept exactly as sign and
+// magnitude, so int64 and uint64 values beyond 2^53 stay distinct; only
+// numbers that have no integer representation are floats.
+type number struct {
+	isFloat bool
+	f       float64
+	neg     bool
+	abs     uint64
+}
+
+// numberOf returns v as a number if it is one. Floats with an integral
+// value in the range of int64 or uint64 are integers.
+func numberOf(v any) (number, bool) {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return intNumber(i), true
+		}
+		if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+			return number{abs: u}, true
+		}
+		f, err := n.Float64()
+		return floatNumber(f), err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intNumber(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return number{abs: rv.Uint()}, true
+	case reflect.Float32, reflect.Float64:
+		return floatNumber(rv.Float()), true
+	}
+
+	return number{}, false
+}
+
+func intNumber(i int64) number {
+	if i < 0 {
+		// -(i+1) cannot overflow for math.MinInt64
+		return number{neg: true, abs: uint64(-(i + 1)) + 1}
+	}
+	return number{abs: uint64(i)}
+}
+
+func floatNumber(f float64) number {
+	if f == math.Trunc(f) {
+		switch {
+		case f >= math.MinInt64 && f < math.MaxInt64:
+			return intNumber(int64(f))
+		case f >= 0 && f < math.MaxUint64:
+			return number{abs: uint64(f)}
+		}
+	}
+	return number{isFloat: true, f: f}
+}
+
+// float returns n as float64, rounding integers beyond 2^53.
+func (n number) float() float64 {
+	switch {
+	case n.isFloat:
+		return n.f
+	case n.neg:
+		return -float64(n.abs)
+	}
+	return float64(n.abs)
+}
+
+// equal reports whether n and m are the same number. Integers are compared
+// exactly; floats are compared as float64.
+func (n number) equal(m number) bool {
+	if n.isFloat || m.isFloat {
+		return n.float() == m.float()
+	}
+	return n == m
+}
+
+// compare returns -1, 0 or +1 depending on whether n is less than, equal to
+// or greater than m. Integers are compared exactly; floats are compared as
+// float64.
+func (n number) compare(m number) int {
+	switch {
+	case n.isFloat || m.isFloat:
+		return cmp.Compare(n.float(), m.float())
+	case n.neg != m.neg && n.neg:
+		return -1
+	case n.neg != m.neg:
+		return 1
+	case n.neg:
+		return cmp.Compare(m.abs, n.abs)
+	}
+	return cmp.Compare(n.abs, m.abs)
+}
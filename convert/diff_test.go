@@ -0,0 +1,115 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	type control struct {
+		ID       string   `json:"id"`
+		Status   string   `json:"status"`
+		Owners   []string `json:"owners"`
+		Evidence []string `json:"evidence,omitempty"`
+		Score    int      `json:"score"`
+	}
+
+	before, err := convert.JSONToDict(control{ID: "AC-1", Status: "draft", Owners: []string{"alice", "bob", "carol"}, Score: 2})
+	require.NoError(t, err)
+
+	after := decodeDict(t, `{
+		"id": "AC-1",
+		"status": "approved",
+		"owners": ["alice"],
+		"evidence": ["doc-1"],
+		"score": 2
+	}`)
+
+	changes := convert.Diff(before, after)
+	assert.Equal(t, []convert.Change{
+		{Type: convert.ChangeAdd, Path: "evidence", To: []any{"doc-1"}},
+		{Type: convert.ChangeRemove, Path: "owners[2]", From: "carol"},
+		{Type: convert.ChangeRemove, Path: "owners[1]", From: "bob"},
+		{Type: convert.ChangeReplace, Path: "status", From: "draft", To: "approved"},
+	}, changes, "score is equal as int and float64")
+
+	for _, c := range changes {
+		if c.Type == convert.ChangeRemove {
+			assert.True(t, convert.Has(before, c.Path), c.Path)
+			continue
+		}
+		got, err := convert.Get(after, c.Path)
+		require.NoError(t, err, c.Path)
+		assert.Equal(t, c.To, got)
+	}
+}
+
+func TestDiff_Nested(t *testing.T) {
+	from := map[string]any{
+		"a": map[string]any{"b": []any{map[string]any{"c": 1}}, "gone": true},
+		"x": "scalar",
+		"k": map[string]any{"dots.in.key": 1},
+	}
+	to := map[string]any{
+		"a": map[string]any{"b": []any{map[string]any{"c": 2}, "new"}},
+		"x": map[string]any{"now": "dict"},
+		"k": map[string]any{"dots.in.key": 2},
+	}
+
+	assert.Equal(t, []convert.Change{
+		{Type: convert.ChangeReplace, Path: "a.b[0].c", From: 1, To: 2},
+		{Type: convert.ChangeAdd, Path: "a.b[1]", To: "new"},
+		{Type: convert.ChangeRemove, Path: "a.gone", From: true},
+		{Type: convert.ChangeReplace, Path: `k["dots.in.key"]`, From: 1, To: 2},
+		{Type: convert.ChangeReplace, Path: "x", From: "scalar", To: map[string]any{"now": "dict"}},
+	}, convert.Diff(from, to))
+
+	assert.Empty(t, convert.Diff(from, from))
+	assert.Empty(t, convert.Diff(nil, map[string]any{}))
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, convert.Equal(map[string]string{"a": "b"}, map[string]any{"a": "b"}))
+	assert.True(t, convert.Equal([]int{1, 2}, []any{float64(1), int64(2)}))
+	assert.False(t, convert.Equal([]any{1}, []any{1, 2}))
+	assert.False(t, convert.Equal(map[string]any{"a": nil}, map[string]any{}))
+	assert.False(t, convert.Equal("1", 1))
+	assert.True(t, convert.Equal(json.Number("2"), uint8(2)))
+	assert.True(t, convert.Equal(float64(3), uint64(3)))
+	assert.False(t, convert.Equal(2.5, 2))
+	assert.False(t, convert.Equal(int64(-1), uint64(math.MaxUint64)))
+}
+
+func TestEqual_BigIntegers(t *testing.T) {
+	const big = int64(1<<53 + 1)
+
+	assert.False(t, convert.Equal(big, big-1))
+	assert.False(t, convert.Equal(uint64(math.MaxUint64), uint64(math.MaxUint64-1)))
+	assert.False(t, convert.Equal(json.Number("9007199254740993"), int64(1<<53)))
+	assert.True(t, convert.Equal(json.Number("9007199254740993"), uint64(big)))
+	assert.True(t, convert.Equal(json.Number("18446744073709551615"), uint64(math.MaxUint64)))
+
+	from := map[string]any{"id": big}
+	to := map[string]any{"id": big - 1}
+
+	assert.Equal(t, []convert.Change{
+		{Type: convert.ChangeReplace, Path: "id", From: big, To: big - 1},
+	}, convert.Diff(from, to))
+
+	patch := convert.CreatePatch(from, to)
+	assert.Equal(t, convert.Patch{{Op: convert.OpReplace, Path: "/id", Value: big - 1}}, patch)
+
+	_, err := convert.Patch{{Op: convert.OpTest, Path: "/id", Value: big - 1}}.Apply(from)
+	require.ErrorIs(t, err, convert.ErrPatchTestFailed)
+
+	_, err = convert.Patch{{Op: convert.OpTest, Path: "/id", Value: json.Number("9007199254740993")}}.Apply(from)
+	require.NoError(t, err)
+}
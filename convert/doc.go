@@ -11,19 +11,37 @@
 //
 //   - High-performance struct to map conversions
 //   - Deep recursive conversion of nested structures
-//   - Slice and array conversion support
+//   - Slice and array conversion support, including lazy iteration and
+//     streaming as NDJSON or a JSON array
 //   - Type-safe map filtering utilities
-//   - JSON tag support for field naming
+//   - Reverse conversion from dictionaries into typed structs
+//   - Path queries and mutations on dictionaries with typed getters
+//   - Flattening of nested dictionaries into separator-joined keys and back
+//   - Deep merge, structural diff and RFC 6902/7386 patches for dictionaries
+//   - encoding/json compatible output, including json tag options, embedded
+//     structs and custom marshalers
+//   - Protobuf messages converted with their protobuf JSON names and mapping
+//   - Cycle detection and depth limits with configurable policies
+//   - Path filtering, redaction and per-type transformers for audit logs
+//   - JSON Schema (draft 2020-12) generation from Go types and validation of
+//     dictionaries against it
+//   - Tabular export as CSV, TSV and Markdown tables
 //   - Zero-allocation optimizations for common cases
 //
 // # Performance Characteristics
 //
-// The functions in this package are optimized for performance:
+// The functions in this package are optimized for performance. Compared to
+// a JSON marshal and unmarshal round trip (the Old benchmarks in
+// dict_bench_test.go, go test -bench JSONToDict -benchmem):
 //
-//   - JSONToDict: ~5x faster than JSON marshaling for simple structs
-//   - JSONToDictSlice: ~4x faster than JSON marshaling for struct slices
-//   - Map inputs: ~1000x faster with zero allocations
-//   - Nested structures: ~4x faster with 72% less memory usage
+//   - BenchmarkJSONToDict_New_Simple: 0.64 µs vs 8.3 µs (~13x), 3 vs 60 allocations
+//   - BenchmarkJSONToDict_New_Nested: 4.7 µs vs 57 µs (~12x), 73% less memory
+//   - BenchmarkJSONToDictSlice_New: 3.8 µs vs 40 µs (~10x), 73% less memory
+//   - BenchmarkJSONToDict_New_Map: 2 ns vs 5.4 µs, zero allocations, as maps
+//     are returned as-is without options
+//
+// Conversion plans are compiled once per type and cached for the lifetime of
+// the process.
 //
 // # Usage Examples
 //
@@ -44,12 +62,75 @@
 //	dicts, err := convert.JSONToDictSlice(users)
 //	// dicts = []any{map[string]any{"name": "John", "age": 30}, ...}
 //
+// Convert a tree with parent pointers, marking the back references:
+//
+//	dict, err := convert.JSONToDict(root, convert.WithCyclePolicy(convert.CycleMarker))
+//	// dict["children"].([]any)[0] = map[string]any{"name": "a", "parent": "[circular]"}
+//
+// Convert for an audit log, dropping tokens and masking fields tagged
+// redact:"true":
+//
+//	dict, err := convert.JSONToDict(user, convert.WithExclude("tokens[*].value"))
+//
+// Stream a large slice to a writer without building the result:
+//
+//	err := convert.EncodeDictSlice(w, controls, convert.StreamNDJSON)
+//
 // Filter map values by type:
 //
 //	data := map[string]any{"count": 42, "name": "test", "active": true}
 //	strings := convert.DictToTypedMap[string](data)
 //	// strings = map[string]string{"name": "test"}
 //
+// Convert map values to a type, reporting the keys that fail:
+//
+//	ports, err := convert.DictToTypedMapCoerce[int](map[string]any{"http": "8080", "grpc": float64(9090)})
+//	// ports = map[string]int{"http": 8080, "grpc": 9090}
+//
+// Convert a map back into a struct:
+//
+//	user, err := convert.DictToStruct[User](map[string]any{"name": "John", "age": float64(30)})
+//	// user = User{Name: "John", Age: 30}
+//
+// Query and modify a dictionary by path:
+//
+//	name, err := convert.GetString(dict, "spec.items[2].name")
+//	err = convert.Set(dict, "metadata.labels.tier", "frontend")
+//	err = convert.Delete(dict, "spec.items[0]")
+//
+// Flatten a dictionary for CSV columns or environment variables:
+//
+//	flat := convert.Flatten(dict, "_")
+//	// flat = map[string]any{"spec_items_0_name": "a", ...}
+//	nested, err := convert.Unflatten(flat, "_")
+//
+// Export dictionaries as a spreadsheet, one row per evidence item:
+//
+//	dicts, err := convert.JSONToDictSlice(controls)
+//	err = convert.WriteTable(w, dicts, convert.TableCSV,
+//		convert.WithColumns("id", "owner.name", "evidence.name"),
+//		convert.WithHeaders(map[string]string{"id": "Control", "owner.name": "Owner"}),
+//		convert.WithExplode("evidence"),
+//	)
+//
+// Cells that spreadsheets would evaluate as formulas, such as "=cmd()", are
+// prefixed with a single quote unless WithFormulaEscaping(false) is given.
+//
+// Validate a user-edited dictionary before converting it:
+//
+//	schema, err := convert.SchemaFor[Config](convert.WithDisallowUnknownFields())
+//	if err := schema.Validate(dict); err != nil {
+//		// err lists every mismatch with its JSON pointer
+//	}
+//	cfg, err := convert.DictToStruct[Config](dict)
+//
+// Merge, diff and patch dictionaries:
+//
+//	merged := convert.Merge(defaults, overrides, convert.WithMergeKey("name"))
+//	changes := convert.Diff(before, after)
+//	patch := convert.CreatePatch(before, after)
+//	result, err := patch.Apply(before)
+//
 // # Design Principles
 //
 // This package follows the Google API Design Guide principles:
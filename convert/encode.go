@@ -0,0 +1,702 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+var (
+	dictType     = reflect.TypeFor[map[string]any]()
+	anySliceType = reflect.TypeFor[[]any]()
+)
+
+// encoderFunc converts a value into the shape encoding/json would produce for
+// it, preserving primitive Go types.
+type encoderFunc func(e *encodeState, v reflect.Value) (any, error)
+
+var (
+	encoderCache    sync.Map // map[reflect.Type]encoderFunc
+	conversionCache sync.Map // map[reflect.Type]bool
+)
+
+// encodeValue converts v using the compiled encoder of its type.
+func encodeValue(e *encodeState, v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	return e.encode(typeEncoder(v.Type()), v)
+}
+
+// typeEncoder returns the cached encoder for t, compiling it on first use.
+func typeEncoder(t reflect.Type) encoderFunc {
+	if fi, ok := encoderCache.Load(t); ok {
+		return fi.(encoderFunc)
+	}
+
+	// To deal with recursive types, populate the cache with an indirect
+	// func before building it. This type waits on the real func (f) to be
+	// ready and then calls it. This indirect func is only used for
+	// recursive types.
+	var (
+		wg sync.WaitGroup
+		f  encoderFunc
+	)
+	wg.Add(1)
+	fi, loaded := encoderCache.LoadOrStore(t, encoderFunc(func(e *encodeState, v reflect.Value) (any, error) {
+		wg.Wait()
+		return f(e, v)
+	}))
+	if loaded {
+		return fi.(encoderFunc)
+	}
+
+	// Compute the real encoder and replace the indirect func with it.
+	f = newTypeEncoder(t, true)
+	wg.Done()
+	encoderCache.Store(t, f)
+
+	return f
+}
+
+// newTypeEncoder compiles the encoder for t. If allowAddr is true, methods
+// with pointer receivers are used for addressable values, as encoding/json
+// does.
+func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
+	if isProtoMessage(t) {
+		return filterDecoded(newProtoEncoder(t))
+	}
+	if t.Kind() != reflect.Ptr && allowAddr && reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return condAddrEncoder(filterDecoded(addrMarshalerEncoder), newTypeEncoder(t, false))
+	}
+	if t.Implements(jsonMarshalerType) {
+		return filterDecoded(marshalerEncoder)
+	}
+	if t.Kind() != reflect.Ptr && allowAddr && reflect.PointerTo(t).Implements(textMarshalerType) {
+		return condAddrEncoder(addrTextMarshalerEncoder, newTypeEncoder(t, false))
+	}
+	if t.Implements(textMarshalerType) {
+		return textMarshalerEncoder
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return newPtrEncoder(t)
+	case reflect.Interface:
+		return interfaceEncoder
+	case reflect.Struct:
+		return newStructEncoder(t)
+	case reflect.Map:
+		return newMapEncoder(t)
+	case reflect.Slice:
+		return newSliceEncoder(t)
+	case reflect.Array:
+		return newArrayEncoder(t)
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return unsupportedTypeEncoder
+	}
+
+	return plainEncoder
+}
+
+func plainEncoder(e *encodeState, v reflect.Value) (any, error) {
+	return v.Interface(), nil
+}
+
+func unsupportedTypeEncoder(e *encodeState, v reflect.Value) (any, error) {
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+}
+
+func condAddrEncoder(canAddrEnc, elseEnc encoderFunc) encoderFunc {
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		if v.CanAddr() {
+			return canAddrEnc(e, v)
+		}
+		return elseEnc(e, v)
+	}
+}
+
+func marshalerEncoder(e *encodeState, v reflect.Value) (any, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+
+	return decodeMarshaled(v.Type(), data, err)
+}
+
+func addrMarshalerEncoder(e *encodeState, v reflect.Value) (any, error) {
+	data, err := v.Addr().Interface().(json.Marshaler).MarshalJSON()
+
+	return decodeMarshaled(v.Type(), data, err)
+}
+
+func textMarshalerEncoder(e *encodeState, v reflect.Value) (any, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", v.Type(), err)
+	}
+
+	return string(text), nil
+}
+
+func addrTextMarshalerEncoder(e *encodeState, v reflect.Value) (any, error) {
+	text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", v.Type(), err)
+	}
+
+	return string(text), nil
+}
+
+// decodeMarshaled decodes the output of a json.Marshaler.
+func decodeMarshaled(t reflect.Type, data []byte, err error) (any, error) {
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", t, err)
+	}
+
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", t, err)
+	}
+
+	return out, nil
+}
+
+func newPtrEncoder(t reflect.Type) encoderFunc {
+	elemEnc := typeEncoder(t.Elem())
+
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		key, seen := e.enterRef(v)
+		if seen {
+			return e.cycle(v)
+		}
+		out, err := e.encode(elemEnc, v.Elem())
+		e.leaveRef(key)
+
+		return out, err
+	}
+}
+
+func interfaceEncoder(e *encodeState, v reflect.Value) (any, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	return encodeValue(e, v.Elem())
+}
+
+// structEncoder is the compiled plan for a struct type.
+type structEncoder struct {
+	fields []fieldEncoder
+}
+
+// fieldEncoder pairs a resolved field with the encoder of its type.
+type fieldEncoder struct {
+	field
+	encode encoderFunc
+}
+
+func newStructEncoder(t reflect.Type) encoderFunc {
+	fields := cachedTypeFields(t)
+	se := structEncoder{fields: make([]fieldEncoder, len(fields))}
+
+	for i, f := range fields {
+		enc := typeEncoder(f.typ)
+		if f.quoted && !hasMarshaler(f.typ) && !(f.typ.Kind() == reflect.Ptr && hasMarshaler(f.typ.Elem())) {
+			enc = quotedEncoder
+		}
+		se.fields[i] = fieldEncoder{field: f, encode: enc}
+	}
+
+	return se.encode
+}
+
+func (se structEncoder) encode(e *encodeState, v reflect.Value) (any, error) {
+	if err := e.descend(1); err != nil {
+		return nil, err
+	}
+	defer e.ascend(1)
+
+	result := make(map[string]any, len(se.fields))
+
+	for i := range se.fields {
+		f := &se.fields[i]
+
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+
+		if (f.omitEmpty && isEmptyValue(fv)) || (f.omitZero && isZeroValue(fv)) {
+			continue
+		}
+
+		if !e.enter(pathSegment{key: f.name}) {
+			continue
+		}
+
+		var value any
+		var err error
+		if f.redact {
+			value = e.redactMask
+		} else {
+			value, err = e.encode(f.encode, fv)
+		}
+		e.leave()
+
+		if err == errSkipValue {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+
+		result[f.name] = value
+	}
+
+	return result, nil
+}
+
+// quotedEncoder converts a field tagged json:",string" into a string holding
+// its JSON encoding.
+func quotedEncoder(e *encodeState, v reflect.Value) (any, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// mapEncoder converts maps into dictionaries.
+type mapEncoder struct {
+	key  func(k reflect.Value) (string, error)
+	elem encoderFunc
+}
+
+func newMapEncoder(t reflect.Type) encoderFunc {
+	if t == dictType {
+		me := newMapConverter(t)
+		return func(e *encodeState, v reflect.Value) (any, error) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			if d := v.Interface().(map[string]any); !e.visitsElems(t) && !dynamicNeedsConversion(d, e.dynamicBudget()) {
+				return d, nil
+			}
+			return me.encode(e, v)
+		}
+	}
+
+	if t.Key().Kind() == reflect.String && !needsConversion(t.Elem()) {
+		return newPreservingEncoder(t)
+	}
+
+	return newMapConverter(t).encode
+}
+
+func newMapConverter(t reflect.Type) mapEncoder {
+	return mapEncoder{key: newKeyEncoder(t.Key()), elem: typeEncoder(t.Elem())}
+}
+
+func (me mapEncoder) encode(e *encodeState, v reflect.Value) (any, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	ref, seen := e.enterRef(v)
+	if seen {
+		return e.cycle(v)
+	}
+	m, err := me.convert(e, v)
+	e.leaveRef(ref)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (me mapEncoder) convert(e *encodeState, v reflect.Value) (map[string]any, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	if err := e.descend(1); err != nil {
+		return nil, err
+	}
+	defer e.ascend(1)
+
+	result := make(map[string]any, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := me.key(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.enter(pathSegment{key: key}) {
+			continue
+		}
+		value, err := e.encode(me.elem, iter.Value())
+		e.leave()
+
+		if err == errSkipValue {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// encodeMap converts any map into a dictionary, including maps whose values
+// are otherwise preserved.
+func encodeMap(e *encodeState, v reflect.Value) (map[string]any, error) {
+	return newMapConverter(v.Type()).convert(e, v)
+}
+
+// newKeyEncoder returns the conversion for map keys of type t. Keys are
+// converted the way encoding/json converts them: strings are used as is, then
+// encoding.TextMarshaler, then integers in base 10.
+func newKeyEncoder(t reflect.Type) func(k reflect.Value) (string, error) {
+	if t.Kind() == reflect.String {
+		return func(k reflect.Value) (string, error) { return k.String(), nil }
+	}
+
+	if t.Implements(textMarshalerType) {
+		return func(k reflect.Value) (string, error) {
+			if k.Kind() == reflect.Ptr && k.IsNil() {
+				return "", nil
+			}
+			text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			return string(text), err
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(k reflect.Value) (string, error) { return strconv.FormatInt(k.Int(), 10), nil }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(k reflect.Value) (string, error) { return strconv.FormatUint(k.Uint(), 10), nil }
+	}
+
+	return func(reflect.Value) (string, error) {
+		return "", fmt.Errorf("%w: map key %s", ErrUnsupportedType, t)
+	}
+}
+
+// newPreservingEncoder returns the encoder for maps, slices and arrays of
+// plain values of type t, which are returned as is unless filters or
+// transformers apply to their elements.
+func newPreservingEncoder(t reflect.Type) encoderFunc {
+	levels := containerDepth(t)
+	nilable := t.Kind() != reflect.Array
+
+	var convert encoderFunc
+	if t.Kind() == reflect.Map {
+		convert = func(e *encodeState, v reflect.Value) (any, error) {
+			return encodeMap(e, v)
+		}
+	} else {
+		elems := newElemsEncoder(t)
+		convert = func(e *encodeState, v reflect.Value) (any, error) {
+			return elems(e, v)
+		}
+	}
+
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		if nilable && v.IsNil() {
+			return nil, nil
+		}
+		if e.visitsElems(t) {
+			return convert(e, v)
+		}
+
+		if err := e.descend(levels); err != nil {
+			return nil, err
+		}
+		e.ascend(levels)
+
+		return v.Interface(), nil
+	}
+}
+
+// containerDepth returns the number of nested map, slice and array levels
+// of t.
+func containerDepth(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return 1 + containerDepth(t.Elem())
+	}
+
+	return 0
+}
+
+func newSliceEncoder(t reflect.Type) encoderFunc {
+	if isByteSlice(t) {
+		return bytesEncoder
+	}
+	if !needsConversion(t.Elem()) {
+		return newPreservingEncoder(t)
+	}
+
+	enc := newArrayEncoder(t)
+	dynamic := t == anySliceType
+
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		if v.IsNil() {
+			return nil, nil
+		}
+		if dynamic {
+			if s := v.Interface().([]any); !e.visitsElems(t) && !dynamicNeedsConversion(s, e.dynamicBudget()) {
+				return s, nil
+			}
+		}
+
+		ref, seen := e.enterRef(v)
+		if seen {
+			return e.cycle(v)
+		}
+		out, err := enc(e, v)
+		e.leaveRef(ref)
+
+		return out, err
+	}
+}
+
+// bytesEncoder converts a byte slice into a base64 string, as encoding/json
+// does.
+func bytesEncoder(e *encodeState, v reflect.Value) (any, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+}
+
+// isByteSlice reports whether t is a slice of bytes that encoding/json
+// encodes as a base64 string, which excludes byte types with marshalers.
+func isByteSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+
+	p := reflect.PointerTo(t.Elem())
+
+	return !p.Implements(jsonMarshalerType) && !p.Implements(textMarshalerType)
+}
+
+func newArrayEncoder(t reflect.Type) encoderFunc {
+	if t.Kind() == reflect.Array && !needsConversion(t.Elem()) {
+		return newPreservingEncoder(t)
+	}
+
+	enc := newElemsEncoder(t)
+
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		return enc(e, v)
+	}
+}
+
+// newElemsEncoder returns a function converting every element of a slice or
+// array of type t.
+func newElemsEncoder(t reflect.Type) func(e *encodeState, v reflect.Value) ([]any, error) {
+	elemEnc := typeEncoder(t.Elem())
+
+	// Boxing fields of an addressable struct copies each of them. Copying
+	// the whole element once makes it unaddressable, unless a pointer
+	// receiver marshaler relies on the address.
+	copyElem := t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct && !usesAddr(t.Elem())
+
+	return func(e *encodeState, v reflect.Value) ([]any, error) {
+		if err := e.descend(1); err != nil {
+			return nil, err
+		}
+		defer e.ascend(1)
+
+		result := make([]any, 0, v.Len())
+
+		for i := range v.Len() {
+			ev := v.Index(i)
+			if copyElem {
+				ev = reflect.ValueOf(ev.Interface())
+			}
+
+			elem, err := encodeElem(e, elemEnc, i, ev)
+			if err == errSkipValue {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, elem)
+		}
+
+		return result, nil
+	}
+}
+
+// encodeElem converts the element v at index i of a slice or array with enc.
+// It returns errSkipValue if the element is filtered out or skipped.
+func encodeElem(e *encodeState, enc encoderFunc, i int, v reflect.Value) (any, error) {
+	if !e.enter(pathSegment{index: i, isIndex: true}) {
+		return nil, errSkipValue
+	}
+	elem, err := e.encode(enc, v)
+	e.leave()
+
+	if err != nil && err != errSkipValue {
+		return nil, fmt.Errorf("[%d]: %w", i, err)
+	}
+
+	return elem, err
+}
+
+// fieldByIndex returns the nested field of v at index. It reports false if
+// the field is unreachable through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	if len(index) == 1 {
+		return v.Field(index[0]), true
+	}
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// usesAddr reports whether encoding t or a value stored inline in it calls a
+// marshaler with a pointer receiver.
+func usesAddr(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface &&
+		(reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) ||
+			reflect.PointerTo(t).Implements(protoMessageType)) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Array:
+		return usesAddr(t.Elem())
+	case reflect.Struct:
+		for _, f := range cachedTypeFields(t) {
+			if usesAddr(f.typ) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasMarshaler reports whether t or *t implements json.Marshaler,
+// encoding.TextMarshaler or proto.Message.
+func hasMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) ||
+		isProtoMessage(t)
+}
+
+// needsConversion reports whether values of type t differ from their
+// dictionary representation. Results are cached per type.
+func needsConversion(t reflect.Type) bool {
+	if b, ok := conversionCache.Load(t); ok {
+		return b.(bool)
+	}
+
+	b := computeNeedsConversion(t)
+	conversionCache.Store(t, b)
+
+	return b
+}
+
+func computeNeedsConversion(t reflect.Type) bool {
+	if hasMarshaler(t) || isByteSlice(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return needsConversion(t.Elem())
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return false
+	}
+
+	return true
+}
+
+// dynamicNeedsConversion reports whether any value nested in a map[string]any
+// or []any needs conversion. Values nested deeper than budget levels, counting
+// v as the first, always need conversion so that they are checked for cycles
+// and the maximum depth.
+func dynamicNeedsConversion(v any, budget int) bool {
+	switch x := v.(type) {
+	case nil, string, bool, int, int64, float64:
+		return false
+	case map[string]any:
+		if budget <= 0 {
+			return true
+		}
+		for _, elem := range x {
+			if dynamicNeedsConversion(elem, budget-1) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		if budget <= 0 {
+			return true
+		}
+		for _, elem := range x {
+			if dynamicNeedsConversion(elem, budget-1) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t := reflect.TypeOf(v)
+
+	return needsConversion(t) || containerDepth(t) > budget
+}
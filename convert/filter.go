@@ -0,0 +1,184 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"reflect"
+)
+
+// filtering reports whether values are filtered by path.
+func (e *encodeState) filtering() bool {
+	return len(e.include) > 0 || len(e.exclude) > 0
+}
+
+// enter moves to the child seg of the current path. It reports false if the
+// child is filtered out, in which case leave must not be called.
+func (e *encodeState) enter(seg pathSegment) bool {
+	if !e.filtering() {
+		return true
+	}
+
+	e.path = append(e.path, seg)
+
+	for _, pattern := range e.exclude {
+		if len(pattern) == len(e.path) && matchPattern(pattern, e.path) {
+			e.path = e.path[:len(e.path)-1]
+			return false
+		}
+	}
+
+	if len(e.include) == 0 {
+		return true
+	}
+
+	// keep ancestors and descendants of included paths
+	for _, pattern := range e.include {
+		if matchPattern(pattern, e.path) {
+			return true
+		}
+	}
+
+	e.path = e.path[:len(e.path)-1]
+
+	return false
+}
+
+// leave reverts a successful enter.
+func (e *encodeState) leave() {
+	if e.filtering() {
+		e.path = e.path[:len(e.path)-1]
+	}
+}
+
+// filtersBelow reports whether values nested in the current path may be
+// filtered out.
+func (e *encodeState) filtersBelow() bool {
+	if !e.filtering() {
+		return false
+	}
+
+	for _, pattern := range e.exclude {
+		if len(pattern) > len(e.path) && matchPattern(pattern, e.path) {
+			return true
+		}
+	}
+
+	if len(e.include) == 0 {
+		return false
+	}
+
+	for _, pattern := range e.include {
+		if len(pattern) <= len(e.path) && matchPattern(pattern, e.path) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchPattern reports whether the shorter of pattern and path is a prefix
+// of the other.
+func matchPattern(pattern, path []pathSegment) bool {
+	for i := range min(len(pattern), len(path)) {
+		p, s := pattern[i], path[i]
+		if p.isIndex != s.isIndex {
+			return false
+		}
+		if p.wildcard {
+			continue
+		}
+		if (p.isIndex && p.index != s.index) || (!p.isIndex && p.key != s.key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encode converts v with the transformer registered for its type or the
+// type it points to, and with enc otherwise.
+func (e *encodeState) encode(enc encoderFunc, v reflect.Value) (any, error) {
+	if e.transformers != nil {
+		if fn, ok := e.transformers[v.Type()]; ok {
+			return fn(v)
+		}
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			if fn, ok := e.transformers[v.Type().Elem()]; ok {
+				return fn(v.Elem())
+			}
+		}
+	}
+
+	return enc(e, v)
+}
+
+// visitsElems reports whether the elements of a map, slice or array of type
+// t have to be converted one by one to apply filters and transformers, even
+// if they need no conversion.
+func (e *encodeState) visitsElems(t reflect.Type) bool {
+	if e.transformers == nil && !e.filtering() {
+		return false
+	}
+	if e.filtersBelow() {
+		return true
+	}
+	if e.transformers == nil {
+		return false
+	}
+
+	for t.Kind() == reflect.Map || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+		if _, ok := e.transformers[t]; ok || t.Kind() == reflect.Interface {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterDecoded wraps an encoder returning freshly decoded values, such as
+// the output of a json.Marshaler, to apply filters to that output.
+func filterDecoded(enc encoderFunc) encoderFunc {
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		out, err := enc(e, v)
+		if err != nil || !e.filtersBelow() {
+			return out, err
+		}
+
+		return e.filterValue(out), nil
+	}
+}
+
+// filterValue removes the values filtered out below the current path from
+// the dicts and slices in v, which must not be shared with the caller.
+func (e *encodeState) filterValue(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, elem := range x {
+			if !e.enter(pathSegment{key: k}) {
+				delete(x, k)
+				continue
+			}
+			if e.filtersBelow() {
+				x[k] = e.filterValue(elem)
+			}
+			e.leave()
+		}
+	case []any:
+		out := x[:0]
+		for i, elem := range x {
+			if !e.enter(pathSegment{index: i, isIndex: true}) {
+				continue
+			}
+			if e.filtersBelow() {
+				elem = e.filterValue(elem)
+			}
+			e.leave()
+			out = append(out, elem)
+		}
+		return out
+	}
+
+	return v
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/kopexa-grc/x/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditToken struct {
+	ID    string `json:"id"`
+	Value string `json:"value" redact:"true"`
+}
+
+type auditUser struct {
+	Name     string            `json:"name"`
+	Password string            `json:"password" redact:"true"`
+	Email    string            `json:"email,omitempty" redact:"true"`
+	Tokens   []auditToken      `json:"tokens"`
+	Labels   map[string]string `json:"labels"`
+	Roles    []string          `json:"roles"`
+	Extra    map[string]any    `json:"extra"`
+}
+
+func newAuditUser() auditUser {
+	return auditUser{
+		Name:     "alice",
+		Password: "hunter2",
+		Tokens:   []auditToken{{ID: "t1", Value: "abc"}, {ID: "t2", Value: "def"}},
+		Labels:   map[string]string{"team": "grc", "cost-center": "42"},
+		Roles:    []string{"admin", "auditor"},
+		Extra:    map[string]any{"session": map[string]any{"id": "s1", "secret": "x"}},
+	}
+}
+
+func TestJSONToDict_Redact(t *testing.T) {
+	result, err := convert.JSONToDict(newAuditUser())
+	require.NoError(t, err)
+	assert.Equal(t, convert.DefaultRedactMask, result["password"])
+	assert.NotContains(t, result, "email", "omitempty applies before redaction")
+	assert.Equal(t, []any{
+		map[string]any{"id": "t1", "value": convert.DefaultRedactMask},
+		map[string]any{"id": "t2", "value": convert.DefaultRedactMask},
+	}, result["tokens"])
+
+	result, err = convert.JSONToDict(newAuditUser(), convert.WithRedactMask(nil))
+	require.NoError(t, err)
+	assert.Contains(t, result, "password")
+	assert.Nil(t, result["password"])
+}
+
+func TestJSONToDict_Filter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []convert.DictOption
+		want map[string]any
+	}{
+		{
+			name: "exclude",
+			opts: []convert.DictOption{
+				convert.WithExclude("password", "tokens[*].value", "labels.cost-center", "extra.*.secret"),
+			},
+			want: map[string]any{
+				"name":   "alice",
+				"tokens": []any{map[string]any{"id": "t1"}, map[string]any{"id": "t2"}},
+				"labels": map[string]any{"team": "grc"},
+				"roles":  []string{"admin", "auditor"},
+				"extra":  map[string]any{"session": map[string]any{"id": "s1"}},
+			},
+		},
+		{
+			name: "exclude index",
+			opts: []convert.DictOption{convert.WithExclude("tokens[0]", "roles[1]")},
+			want: map[string]any{
+				"name":     "alice",
+				"password": convert.DefaultRedactMask,
+				"tokens":   []any{map[string]any{"id": "t2", "value": convert.DefaultRedactMask}},
+				"labels":   map[string]string{"team": "grc", "cost-center": "42"},
+				"roles":    []any{"admin"},
+				"extra":    map[string]any{"session": map[string]any{"id": "s1", "secret": "x"}},
+			},
+		},
+		{
+			name: "include",
+			opts: []convert.DictOption{convert.WithInclude("name", "tokens[*].id", "labels")},
+			want: map[string]any{
+				"name":   "alice",
+				"tokens": []any{map[string]any{"id": "t1"}, map[string]any{"id": "t2"}},
+				"labels": map[string]string{"team": "grc", "cost-center": "42"},
+			},
+		},
+		{
+			name: "exclude takes precedence",
+			opts: []convert.DictOption{
+				convert.WithInclude("extra"),
+				convert.WithExclude("extra.session.secret"),
+			},
+			want: map[string]any{
+				"extra": map[string]any{"session": map[string]any{"id": "s1"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := newAuditUser()
+
+			result, err := convert.JSONToDict(in, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+			assert.Equal(t, newAuditUser(), in, "input must not be modified")
+		})
+	}
+}
+
+func TestJSONToDict_FilterMarshaled(t *testing.T) {
+	in := struct {
+		Cred *vault.Credential `json:"cred"`
+	}{Cred: &vault.Credential{SecretId: "sec-1", User: "admin", Secret: []byte("s3cr3t")}}
+
+	result, err := convert.JSONToDict(in, convert.WithExclude("cred.secret"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"cred": map[string]any{"secretId": "sec-1", "user": "admin"},
+	}, result)
+}
+
+func TestJSONToDict_Transformer(t *testing.T) {
+	type event struct {
+		At      time.Time         `json:"at"`
+		Expires *time.Time        `json:"expires"`
+		Actor   string            `json:"actor"`
+		Tags    []string          `json:"tags"`
+		Meta    map[string]string `json:"meta"`
+	}
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	in := event{
+		At:      at,
+		Expires: &at,
+		Actor:   "Alice",
+		Tags:    []string{"Login"},
+		Meta:    map[string]string{"ip": "10.0.0.1"},
+	}
+
+	result, err := convert.JSONToDict(in,
+		convert.WithTransformer(func(t time.Time) (any, error) { return t.Unix(), nil }),
+		convert.WithTransformer(func(s string) (any, error) { return strings.ToLower(s), nil }),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"at":      at.Unix(),
+		"expires": at.Unix(),
+		"actor":   "alice",
+		"tags":    []any{"login"},
+		"meta":    map[string]any{"ip": "10.0.0.1"},
+	}, result)
+	assert.Equal(t, []string{"Login"}, in.Tags, "input must not be modified")
+
+	_, err = convert.JSONToDict(in, convert.WithTransformer(func(time.Time) (any, error) {
+		return nil, errMarshal
+	}))
+	require.ErrorIs(t, err, errMarshal)
+	assert.Contains(t, err.Error(), "at")
+}
+
+func TestJSONToDict_FilterErrors(t *testing.T) {
+	_, err := convert.JSONToDict(newAuditUser(), convert.WithExclude("tokens[x]"))
+	assert.ErrorIs(t, err, convert.ErrInvalidPath)
+
+	_, err = convert.JSONToDictSlice([]auditUser{}, convert.WithInclude(""))
+	assert.ErrorIs(t, err, convert.ErrInvalidPath)
+}
+
+func TestJSONToDictSlice_Filter(t *testing.T) {
+	users := []auditUser{newAuditUser(), newAuditUser()}
+
+	result, err := convert.JSONToDictSlice(users, convert.WithInclude("[*].name"), convert.WithExclude("[1]"))
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"name": "alice"}}, result)
+}
@@ -0,0 +1,417 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultSeparator is the key separator used by Flatten and Unflatten when
+// an empty separator is given.
+const DefaultSeparator = "."
+
+// IndexStyle controls how slice indexes appear in flattened keys.
+type IndexStyle int
+
+const (
+	// IndexDotted writes indexes as regular key segments, e.g. "a.0.c".
+	IndexDotted IndexStyle = iota
+
+	// IndexBrackets writes indexes in brackets, e.g. "a[0].c".
+	IndexBrackets
+
+	// indexJoined omits indexes, so that all elements of a slice share the
+	// key of the slice. It is used by ToTable to join them into one cell.
+	indexJoined IndexStyle = -1
+)
+
+// FlattenOption configures Flatten and Unflatten.
+type FlattenOption func(*flattenOptions)
+
+type flattenOptions struct {
+	sep        string
+	indexes    IndexStyle
+	maxDepth   int
+	indexLimit int
+}
+
+// WithIndexStyle sets how slice indexes appear in flattened keys. The
+// default is IndexDotted.
+func WithIndexStyle(style IndexStyle) FlattenOption {
+	return func(o *flattenOptions) {
+		o.indexes = style
+	}
+}
+
+// WithFlattenDepth limits flattened keys to depth segments. Values nested
+// deeper are kept as they are under the key of their ancestor at that
+// depth. Zero or a negative depth means no limit.
+func WithFlattenDepth(depth int) FlattenOption {
+	return func(o *flattenOptions) {
+		o.maxDepth = max(depth, 0)
+	}
+}
+
+// WithIndexLimit makes Unflatten reject bracketed indexes of limit or more,
+// so that a single key cannot allocate a huge slice. The default limit is
+// the number of flattened keys, which every index produced by Flatten is
+// below; raise it to accept sparse indexes. Zero or a negative limit keeps
+// the default.
+func WithIndexLimit(limit int) FlattenOption {
+	return func(o *flattenOptions) {
+		o.indexLimit = max(limit, 0)
+	}
+}
+
+func newFlattenOptions(sep string, opts []FlattenOption) *flattenOptions {
+	options := &flattenOptions{sep: sep}
+	if options.sep == "" {
+		options.sep = DefaultSeparator
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// Flatten returns the leaf values of d keyed by their joined paths.
+//
+// Nested dicts and slices are descended into, joining keys and indexes
+// with sep (DefaultSeparator if empty). Empty dicts and slices are kept as
+// values so that Unflatten can restore them. Maps with string keys and
+// slices of any type are flattened, so values preserved by JSONToDict such
+// as map[string]string or []string are flattened as well; []byte values are
+// kept as they are. Convert structs with JSONToDict first.
+//
+// Keys that contain sep are joined as they are, so Unflatten cannot restore
+// them unambiguously.
+//
+// # Examples
+//
+//	dict := map[string]any{
+//		"spec": map[string]any{
+//			"items":  []any{map[string]any{"name": "a"}},
+//			"labels": map[string]any{},
+//		},
+//	}
+//
+//	flat := Flatten(dict, ".")
+//	// flat: map[string]any{"spec.items.0.name": "a", "spec.labels": map[string]any{}}
+//
+//	flat = Flatten(dict, "__", WithIndexStyle(IndexBrackets))
+//	// flat: map[string]any{"spec__items[0]__name": "a", "spec__labels": map[string]any{}}
+func Flatten(d map[string]any, sep string, opts ...FlattenOption) map[string]any {
+	if d == nil {
+		return nil
+	}
+
+	o := newFlattenOptions(sep, opts)
+	flat := make(map[string]any, len(d))
+
+	for k, v := range d {
+		o.flattenValue(k, 1, v, func(key string, v any) {
+			flat[key] = cloneValue(v)
+		})
+	}
+
+	return flat
+}
+
+// flattenValue calls leaf with the joined key and value of every leaf of v,
+// which is stored at key and depth.
+func (o *flattenOptions) flattenValue(key string, depth int, v any, leaf func(key string, v any)) {
+	if _, ok := v.([]byte); !ok && (o.maxDepth == 0 || depth < o.maxDepth) {
+		if d, ok := asDict(v); ok && len(d) > 0 {
+			for k, c := range d {
+				o.flattenValue(key+o.sep+k, depth+1, c, leaf)
+			}
+			return
+		}
+
+		if s, ok := asSlice(v); ok && len(s) > 0 {
+			for i, c := range s {
+				o.flattenValue(o.indexKey(key, i), depth+1, c, leaf)
+			}
+			return
+		}
+	}
+
+	leaf(key, v)
+}
+
+// indexKey appends the slice index i to key.
+func (o *flattenOptions) indexKey(key string, i int) string {
+	switch o.indexes {
+	case IndexBrackets:
+		return key + "[" + strconv.Itoa(i) + "]"
+	case indexJoined:
+		return key
+	}
+
+	return key + o.sep + strconv.Itoa(i)
+}
+
+// Unflatten rebuilds nested dicts and slices from keys produced by Flatten.
+// The separator and options must match the ones used for flattening.
+//
+// With IndexDotted, a dict whose keys are exactly the indexes 0 to n-1 is
+// restored as a slice. With IndexBrackets, bracketed indexes always create
+// slices and indexes missing from the input are filled with nil, up to the
+// limit set by WithIndexLimit. With WithFlattenDepth, keys are split into at
+// most that many segments. The result is always a dict, even if all keys are
+// indexes.
+//
+// # Errors
+//
+// Returns a *PathError naming the conflicting key, wrapping ErrTypeMismatch
+// if a key is used both as a value and as a parent of other keys, or as both
+// a dict and a slice. Returns ErrInvalidPath for malformed bracketed
+// indexes and indexes beyond the index limit.
+//
+// # Examples
+//
+//	dict, err := Unflatten(map[string]any{
+//		"spec.items.0.name": "a",
+//		"spec.items.1.name": "b",
+//		"spec.replicas":     3,
+//	}, ".")
+//	// dict: map[string]any{"spec": map[string]any{
+//	//   "items":    []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}},
+//	//   "replicas": 3,
+//	// }}
+func Unflatten(flat map[string]any, sep string, opts ...FlattenOption) (map[string]any, error) {
+	if flat == nil {
+		return nil, nil
+	}
+
+	o := newFlattenOptions(sep, opts)
+	if o.indexLimit == 0 {
+		o.indexLimit = len(flat)
+	}
+
+	// sorted keys make conflict errors deterministic
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	root := &flatNode{kind: flatDict}
+	for _, key := range keys {
+		segs, err := o.splitKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := root.insert(key, segs, flat[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return root.buildDict(o), nil
+}
+
+// splitKey splits a flattened key into its segments.
+func (o *flattenOptions) splitKey(key string) ([]pathSegment, error) {
+	parts := strings.Split(key, o.sep)
+
+	var segs []pathSegment
+	for i, part := range parts {
+		if o.indexes != IndexBrackets {
+			segs = append(segs, pathSegment{key: part})
+			continue
+		}
+
+		name, indexes, _ := strings.Cut(part, "[")
+		if indexes != "" || strings.HasSuffix(part, "[") {
+			indexes = "[" + indexes
+		}
+		if i > 0 || name != "" || indexes == "" {
+			segs = append(segs, pathSegment{key: name})
+		}
+
+		for indexes != "" {
+			end := strings.IndexByte(indexes, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w %q: missing ]", ErrInvalidPath, key)
+			}
+
+			index, err := strconv.Atoi(indexes[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("%w %q: index must be a non-negative integer", ErrInvalidPath, key)
+			}
+			if o.indexLimit > 0 && index >= o.indexLimit {
+				return nil, fmt.Errorf("%w %q: index %d exceeds the limit of %d", ErrInvalidPath, key, index, o.indexLimit)
+			}
+			segs = append(segs, pathSegment{index: index, isIndex: true})
+
+			indexes = indexes[end+1:]
+			if indexes != "" && indexes[0] != '[' {
+				return nil, fmt.Errorf("%w %q: expected [ after index", ErrInvalidPath, key)
+			}
+		}
+	}
+
+	if o.maxDepth > 0 && len(segs) > o.maxDepth {
+		segs = append(segs[:o.maxDepth-1], pathSegment{key: o.joinKey(segs[o.maxDepth-1:])})
+	}
+
+	return segs, nil
+}
+
+// joinKey is the inverse of splitKey.
+func (o *flattenOptions) joinKey(segs []pathSegment) string {
+	var b strings.Builder
+
+	for i, seg := range segs {
+		switch {
+		case seg.isIndex && o.indexes == IndexBrackets:
+			b.WriteString("[" + strconv.Itoa(seg.index) + "]")
+			continue
+		case i > 0:
+			b.WriteString(o.sep)
+		}
+
+		if seg.isIndex {
+			b.WriteString(strconv.Itoa(seg.index))
+		} else {
+			b.WriteString(seg.key)
+		}
+	}
+
+	return b.String()
+}
+
+type flatKind int
+
+const (
+	flatUnset flatKind = iota
+	flatLeaf
+	flatDict
+	flatSlice
+)
+
+// flatNode is an intermediate tree node built by Unflatten.
+type flatNode struct {
+	kind     flatKind
+	value    any
+	children map[string]*flatNode
+	indexes  map[int]*flatNode
+}
+
+// insert stores value at segs below n. key is the flattened key for errors.
+func (n *flatNode) insert(key string, segs []pathSegment, value any) error {
+	conflict := func(reason string) error {
+		return &PathError{Path: key, Err: fmt.Errorf("%w: %s", ErrTypeMismatch, reason)}
+	}
+
+	for _, seg := range segs {
+		kind := flatDict
+		if seg.isIndex {
+			kind = flatSlice
+		}
+
+		switch n.kind {
+		case flatUnset:
+			n.kind = kind
+		case flatLeaf:
+			return conflict("parent is a value")
+		case kind:
+		default:
+			return conflict("key is used for both a dict and a slice")
+		}
+
+		var c *flatNode
+		if seg.isIndex {
+			if n.indexes == nil {
+				n.indexes = make(map[int]*flatNode)
+			}
+			if c = n.indexes[seg.index]; c == nil {
+				c = &flatNode{}
+				n.indexes[seg.index] = c
+			}
+		} else {
+			if n.children == nil {
+				n.children = make(map[string]*flatNode)
+			}
+			if c = n.children[seg.key]; c == nil {
+				c = &flatNode{}
+				n.children[seg.key] = c
+			}
+		}
+		n = c
+	}
+
+	if n.kind != flatUnset {
+		return conflict("value is also a parent of other keys")
+	}
+
+	n.kind = flatLeaf
+	n.value = cloneValue(value)
+
+	return nil
+}
+
+// build returns the value represented by n.
+func (n *flatNode) build(o *flattenOptions) any {
+	switch n.kind {
+	case flatLeaf:
+		return n.value
+	case flatSlice:
+		size := 0
+		for i := range n.indexes {
+			size = max(size, i+1)
+		}
+
+		s := make([]any, size)
+		for i, c := range n.indexes {
+			s[i] = c.build(o)
+		}
+		return s
+	}
+
+	if s, ok := n.dottedSlice(o); ok {
+		return s
+	}
+
+	return n.buildDict(o)
+}
+
+// buildDict returns the children of n as a dict.
+func (n *flatNode) buildDict(o *flattenOptions) map[string]any {
+	d := make(map[string]any, len(n.children))
+	for k, c := range n.children {
+		d[k] = c.build(o)
+	}
+
+	return d
+}
+
+// dottedSlice returns the children of n as a slice if their keys are
+// exactly the indexes 0 to n-1 written with IndexDotted.
+func (n *flatNode) dottedSlice(o *flattenOptions) ([]any, bool) {
+	if o.indexes != IndexDotted || len(n.children) == 0 {
+		return nil, false
+	}
+
+	for k := range n.children {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(n.children) || strconv.Itoa(i) != k {
+			return nil, false
+		}
+	}
+
+	s := make([]any, len(n.children))
+	for k, c := range n.children {
+		i, _ := strconv.Atoi(k)
+		s[i] = c.build(o)
+	}
+
+	return s, true
+}
@@ -0,0 +1,244 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFlattenDict() map[string]any {
+	return map[string]any{
+		"name": "policy",
+		"spec": map[string]any{
+			"controls": []any{
+				map[string]any{"id": "AC-1", "owners": []string{"alice", "bob"}},
+				map[string]any{"id": "AC-2", "owners": []string{}},
+			},
+			"labels": map[string]string{"env": "prod"},
+			"empty":  map[string]any{},
+		},
+		"score": nil,
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  string
+		opts []convert.FlattenOption
+		want map[string]any
+	}{
+		{
+			name: "dotted",
+			sep:  ".",
+			want: map[string]any{
+				"name":                     "policy",
+				"spec.controls.0.id":       "AC-1",
+				"spec.controls.0.owners.0": "alice",
+				"spec.controls.0.owners.1": "bob",
+				"spec.controls.1.id":       "AC-2",
+				"spec.controls.1.owners":   []string{},
+				"spec.labels.env":          "prod",
+				"spec.empty":               map[string]any{},
+				"score":                    nil,
+			},
+		},
+		{
+			name: "brackets with custom separator",
+			sep:  "__",
+			opts: []convert.FlattenOption{convert.WithIndexStyle(convert.IndexBrackets)},
+			want: map[string]any{
+				"name":                         "policy",
+				"spec__controls[0]__id":        "AC-1",
+				"spec__controls[0]__owners[0]": "alice",
+				"spec__controls[0]__owners[1]": "bob",
+				"spec__controls[1]__id":        "AC-2",
+				"spec__controls[1]__owners":    []string{},
+				"spec__labels__env":            "prod",
+				"spec__empty":                  map[string]any{},
+				"score":                        nil,
+			},
+		},
+		{
+			name: "max depth",
+			opts: []convert.FlattenOption{convert.WithFlattenDepth(3)},
+			want: map[string]any{
+				"name":            "policy",
+				"spec.controls.0": map[string]any{"id": "AC-1", "owners": []string{"alice", "bob"}},
+				"spec.controls.1": map[string]any{"id": "AC-2", "owners": []string{}},
+				"spec.labels.env": "prod",
+				"spec.empty":      map[string]any{},
+				"score":           nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFlattenDict()
+
+			flat := convert.Flatten(d, tt.sep, tt.opts...)
+			assert.Equal(t, tt.want, flat)
+			assert.Equal(t, newFlattenDict(), d, "input must not be modified")
+
+			restored, err := convert.Unflatten(flat, tt.sep, tt.opts...)
+			require.NoError(t, err)
+			assert.True(t, convert.Equal(d, restored), "round trip: %v", restored)
+		})
+	}
+
+	assert.Nil(t, convert.Flatten(nil, "."))
+	assert.Equal(t, map[string]any{"a.raw": []byte("raw")},
+		convert.Flatten(map[string]any{"a": map[string]any{"raw": []byte("raw")}}, "."),
+		"byte slices are leaves")
+}
+
+func TestUnflatten(t *testing.T) {
+	t.Run("dotted indexes become slices only when contiguous", func(t *testing.T) {
+		d, err := convert.Unflatten(map[string]any{
+			"items.0":   "a",
+			"items.1":   "b",
+			"codes.1":   "x",
+			"codes.2":   "y",
+			"padded.0":  "z",
+			"padded.01": "w",
+		}, "")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"items":  []any{"a", "b"},
+			"codes":  map[string]any{"1": "x", "2": "y"},
+			"padded": map[string]any{"0": "z", "01": "w"},
+		}, d)
+	})
+
+	t.Run("bracket indexes fill gaps", func(t *testing.T) {
+		d, err := convert.Unflatten(map[string]any{
+			"items[2].name": "c",
+			"items[0].name": "a",
+			"matrix[0][1]":  1,
+			"codes.1":       "x",
+		}, ".", convert.WithIndexStyle(convert.IndexBrackets))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"items":  []any{map[string]any{"name": "a"}, nil, map[string]any{"name": "c"}},
+			"matrix": []any{[]any{nil, 1}},
+			"codes":  map[string]any{"1": "x"},
+		}, d)
+	})
+
+	t.Run("index limit can be raised for sparse indexes", func(t *testing.T) {
+		d, err := convert.Unflatten(map[string]any{"a[3]": 1}, ".",
+			convert.WithIndexStyle(convert.IndexBrackets), convert.WithIndexLimit(4))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": []any{nil, nil, nil, 1}}, d)
+	})
+
+	t.Run("root stays a dict", func(t *testing.T) {
+		d, err := convert.Unflatten(map[string]any{"0": "a", "1": "b"}, ".")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"0": "a", "1": "b"}, d)
+
+		d, err = convert.Unflatten(convert.Flatten(map[string]any{"0": []any{"a"}}, "."), ".")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"0": []any{"a"}}, d)
+	})
+
+	t.Run("max depth keeps remaining segments in the key", func(t *testing.T) {
+		d, err := convert.Unflatten(map[string]any{"a.b.c.d": 1}, ".", convert.WithFlattenDepth(2))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": map[string]any{"b.c.d": 1}}, d)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		d, err := convert.Unflatten(nil, ".")
+		require.NoError(t, err)
+		assert.Nil(t, d)
+	})
+}
+
+func TestUnflatten_Errors(t *testing.T) {
+	brackets := convert.WithIndexStyle(convert.IndexBrackets)
+
+	tests := []struct {
+		name     string
+		flat     map[string]any
+		opts     []convert.FlattenOption
+		wantErr  error
+		wantPath string
+	}{
+		{
+			name:     "value and parent",
+			flat:     map[string]any{"a": 1, "a.b": 2},
+			wantErr:  convert.ErrTypeMismatch,
+			wantPath: "a.b",
+		},
+		{
+			name:     "parent and value",
+			flat:     map[string]any{"a.b.c": 1, "a.b": 2},
+			wantErr:  convert.ErrTypeMismatch,
+			wantPath: "a.b.c",
+		},
+		{
+			name:     "dict and slice",
+			flat:     map[string]any{"a[0]": 1, "a.b": 2},
+			opts:     []convert.FlattenOption{brackets},
+			wantErr:  convert.ErrTypeMismatch,
+			wantPath: "a[0]",
+		},
+		{
+			name:     "root index",
+			flat:     map[string]any{"[0]": 1},
+			opts:     []convert.FlattenOption{brackets},
+			wantErr:  convert.ErrTypeMismatch,
+			wantPath: "[0]",
+		},
+		{
+			name:    "index beyond the number of keys",
+			flat:    map[string]any{"a[99999999999]": 1},
+			opts:    []convert.FlattenOption{brackets},
+			wantErr: convert.ErrInvalidPath,
+		},
+		{
+			name:    "index beyond the limit",
+			flat:    map[string]any{"a[10]": 1},
+			opts:    []convert.FlattenOption{brackets, convert.WithIndexLimit(10)},
+			wantErr: convert.ErrInvalidPath,
+		},
+		{
+			name:    "unterminated index",
+			flat:    map[string]any{"a[0": 1},
+			opts:    []convert.FlattenOption{brackets},
+			wantErr: convert.ErrInvalidPath,
+		},
+		{
+			name:    "invalid index",
+			flat:    map[string]any{"a[x]": 1},
+			opts:    []convert.FlattenOption{brackets},
+			wantErr: convert.ErrInvalidPath,
+		},
+		{
+			name:    "trailing characters",
+			flat:    map[string]any{"a[0]b": 1},
+			opts:    []convert.FlattenOption{brackets},
+			wantErr: convert.ErrInvalidPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := convert.Unflatten(tt.flat, ".", tt.opts...)
+			require.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantPath != "" {
+				var pathErr *convert.PathError
+				require.ErrorAs(t, err, &pathErr)
+				assert.Equal(t, tt.wantPath, pathErr.Path)
+			}
+		})
+	}
+}
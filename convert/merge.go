@@ -0,0 +1,357 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"reflect"
+	"slices"
+)
+
+// SliceStrategy controls how Merge combines slices present in both dicts.
+type SliceStrategy int
+
+const (
+	// SliceReplace replaces the destination slice with the source slice.
+	SliceReplace SliceStrategy = iota
+
+	// SliceAppend appends the source elements to the destination slice.
+	SliceAppend
+
+	// SliceMergeByKey merges dict elements with equal values for the merge
+	// key and appends all other source elements. See WithMergeKey.
+	SliceMergeByKey
+)
+
+// DefaultMergeKey is the element key used by SliceMergeByKey unless
+// WithMergeKey is given.
+const DefaultMergeKey = "name"
+
+// MergeOption configures Merge.
+type MergeOption func(*mergeOptions)
+
+type mergeOptions struct {
+	slices   SliceStrategy
+	mergeKey string
+}
+
+// WithSliceStrategy sets how slices present in both dicts are combined.
+// The default is SliceReplace.
+func WithSliceStrategy(strategy SliceStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.slices = strategy
+	}
+}
+
+// WithMergeKey merges slices by key, matching dict elements on the given
+// key. It implies SliceMergeByKey.
+func WithMergeKey(key string) MergeOption {
+	return func(o *mergeOptions) {
+		o.slices = SliceMergeByKey
+		o.mergeKey = key
+	}
+}
+
+// Merge deep-merges src into dst and returns the result as a new dict.
+// Neither input is modified.
+//
+// # Merge Rules
+//
+//   - Nested dicts are merged recursively
+//   - Slices present in both dicts are combined according to the slice
+//     strategy (see WithSliceStrategy and WithMergeKey)
+//   - All other source values, including nil, replace the destination value
+//
+// Maps with string keys and slices of any type are merged, so values
+// preserved by JSONToDict such as map[string]string participate as well.
+//
+// # Examples
+//
+//	base := map[string]any{
+//		"controls": []any{map[string]any{"name": "AC-1", "status": "draft"}},
+//		"owner":    map[string]any{"team": "grc", "email": "grc@example.com"},
+//	}
+//	override := map[string]any{
+//		"controls": []any{map[string]any{"name": "AC-1", "status": "approved"}},
+//		"owner":    map[string]any{"email": "audit@example.com"},
+//	}
+//
+//	merged := Merge(base, override, WithMergeKey("name"))
+//	// merged: map[string]any{
+//	//   "controls": []any{map[string]any{"name": "AC-1", "status": "approved"}},
+//	//   "owner":    map[string]any{"team": "grc", "email": "audit@example.com"},
+//	// }
+func Merge(dst, src map[string]any, opts ...MergeOption) map[string]any {
+	options := mergeOptions{mergeKey: DefaultMergeKey}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if dst == nil && src == nil {
+		return nil
+	}
+
+	return mergeDicts(cloneDict(dst), src, &options)
+}
+
+// mergeDicts merges src into dst, which is owned by the caller.
+func mergeDicts(dst, src map[string]any, o *mergeOptions) map[string]any {
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+
+	for k, sv := range src {
+		dst[k] = mergeValues(dst[k], sv, o)
+	}
+
+	return dst
+}
+
+func mergeValues(dv, sv any, o *mergeOptions) any {
+	// dv is owned by the caller, so it can be modified in place
+	if dd, ok := asDict(dv); ok {
+		if sd, ok := asDict(sv); ok {
+			return mergeDicts(dd, sd, o)
+		}
+	}
+
+	if ds, ok := asSlice(dv); ok {
+		if ss, ok := asSlice(sv); ok {
+			switch o.slices {
+			case SliceAppend:
+				return append(ds, cloneSlice(ss)...)
+			case SliceMergeByKey:
+				return mergeByKey(ds, ss, o)
+			}
+		}
+	}
+
+	return cloneValue(sv)
+}
+
+// mergeByKey merges dict elements of src into the dict element of dst with
+// the same merge key value and appends all other elements. dst is owned by
+// the caller.
+func mergeByKey(dst, src []any, o *mergeOptions) []any {
+	for _, sv := range src {
+		sd, ok := asDict(sv)
+		key, hasKey := sd[o.mergeKey]
+		if !ok || !hasKey {
+			dst = append(dst, cloneValue(sv))
+			continue
+		}
+
+		i := slices.IndexFunc(dst, func(dv any) bool {
+			dd, ok := asDict(dv)
+			if !ok {
+				return false
+			}
+			dk, ok := dd[o.mergeKey]
+			return ok && equalValues(dk, key)
+		})
+		if i < 0 {
+			dst = append(dst, cloneValue(sv))
+			continue
+		}
+
+		dd, _ := asDict(dst[i])
+		dst[i] = mergeDicts(dd, sd, o)
+	}
+
+	return dst
+}
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to d and returns the
+// result as a new dict. Neither input is modified.
+//
+// Keys with a nil value in patch are removed, nested dicts are patched
+// recursively and all other values replace the target value. Slices are
+// always replaced.
+//
+// # Examples
+//
+//	doc := map[string]any{"title": "Goodbye!", "author": map[string]any{"givenName": "John", "familyName": "Doe"}}
+//	patch := map[string]any{"title": "Hello!", "author": map[string]any{"familyName": nil}}
+//
+//	result := MergePatch(doc, patch)
+//	// result: map[string]any{"title": "Hello!", "author": map[string]any{"givenName": "John"}}
+func MergePatch(d, patch map[string]any) map[string]any {
+	return applyMergePatch(cloneDict(d), patch)
+}
+
+func applyMergePatch(target, patch map[string]any) map[string]any {
+	if target == nil {
+		target = make(map[string]any, len(patch))
+	}
+
+	for k, pv := range patch {
+		if pv == nil {
+			delete(target, k)
+			continue
+		}
+
+		pd, ok := asDict(pv)
+		if !ok {
+			target[k] = cloneValue(pv)
+			continue
+		}
+
+		// target is owned by the caller, so it can be modified in place
+		td, _ := asDict(target[k])
+		target[k] = applyMergePatch(td, pd)
+	}
+
+	return target
+}
+
+// CreateMergePatch returns the RFC 7386 JSON Merge Patch that transforms
+// from into to. Applying it with MergePatch to from yields to, except that
+// nil values in to cannot be represented and are removed.
+//
+// # Examples
+//
+//	from := map[string]any{"status": "draft", "owner": "alice", "tags": []any{"a"}}
+//	to := map[string]any{"status": "approved", "tags": []any{"a"}}
+//
+//	patch := CreateMergePatch(from, to)
+//	// patch: map[string]any{"status": "approved", "owner": nil}
+func CreateMergePatch(from, to map[string]any) map[string]any {
+	patch := make(map[string]any)
+
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			patch[k] = nil
+		}
+	}
+
+	for k, tv := range to {
+		fv, ok := from[k]
+		if ok && equalValues(fv, tv) {
+			continue
+		}
+
+		fd, fromDict := asDict(fv)
+		td, toDict := asDict(tv)
+		if ok && fromDict && toDict {
+			patch[k] = CreateMergePatch(fd, td)
+			continue
+		}
+
+		patch[k] = cloneValue(tv)
+	}
+
+	return patch
+}
+
+// asDict returns v as map[string]any. Maps with other string key and value
+// types are copied.
+func asDict(v any) (map[string]any, bool) {
+	if d, ok := v.(map[string]any); ok {
+		return d, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+
+	d := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		d[iter.Key().String()] = iter.Value().Interface()
+	}
+
+	return d, true
+}
+
+// asSlice returns v as []any. Slices and arrays of other types are copied.
+func asSlice(v any) ([]any, bool) {
+	if s, ok := v.([]any); ok {
+		return s, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	s := make([]any, rv.Len())
+	for i := range s {
+		s[i] = rv.Index(i).Interface()
+	}
+
+	return s, true
+}
+
+// cloneValue returns a deep copy of the dicts and slices in v.
+func cloneValue(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		return cloneDict(x)
+	case []any:
+		return cloneSlice(x)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), cloneElem(iter.Value()))
+		}
+		return m.Interface()
+	case reflect.Slice:
+		if rv.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			s.Index(i).Set(cloneElem(rv.Index(i)))
+		}
+		return s.Interface()
+	}
+
+	return v
+}
+
+// cloneElem returns a deep copy of an element of a typed map or slice.
+func cloneElem(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Interface:
+		if c := cloneValue(v.Interface()); c != nil {
+			return reflect.ValueOf(c).Convert(v.Type())
+		}
+	}
+
+	return v
+}
+
+func cloneDict(d map[string]any) map[string]any {
+	if d == nil {
+		return nil
+	}
+
+	c := make(map[string]any, len(d))
+	for k, v := range d {
+		c[k] = cloneValue(v)
+	}
+
+	return c
+}
+
+func cloneSlice(s []any) []any {
+	if s == nil {
+		return nil
+	}
+
+	c := make([]any, len(s))
+	for i, v := range s {
+		c[i] = cloneValue(v)
+	}
+
+	return c
+}
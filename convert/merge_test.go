@@ -0,0 +1,165 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMergeBase() map[string]any {
+	return map[string]any{
+		"title": "policy",
+		"owner": map[string]any{"team": "grc", "email": "grc@example.com"},
+		"controls": []any{
+			map[string]any{"name": "AC-1", "status": "draft", "evidence": []any{"doc-1"}},
+			map[string]any{"name": "AC-2", "status": "draft"},
+		},
+		"labels": map[string]string{"env": "prod"},
+		"tags":   []string{"iso"},
+	}
+}
+
+func TestMerge(t *testing.T) {
+	override := map[string]any{
+		"owner": map[string]any{"email": "audit@example.com"},
+		"controls": []any{
+			map[string]any{"name": "AC-2", "status": "approved"},
+			map[string]any{"name": "AC-3", "status": "draft"},
+		},
+		"labels": map[string]any{"tier": "gold"},
+		"tags":   []any{"soc2"},
+		"title":  nil,
+	}
+
+	tests := []struct {
+		name         string
+		opts         []convert.MergeOption
+		wantControls []any
+		wantTags     []any
+	}{
+		{
+			name:         "replace",
+			wantControls: override["controls"].([]any),
+			wantTags:     []any{"soc2"},
+		},
+		{
+			name: "append",
+			opts: []convert.MergeOption{convert.WithSliceStrategy(convert.SliceAppend)},
+			wantControls: []any{
+				map[string]any{"name": "AC-1", "status": "draft", "evidence": []any{"doc-1"}},
+				map[string]any{"name": "AC-2", "status": "draft"},
+				map[string]any{"name": "AC-2", "status": "approved"},
+				map[string]any{"name": "AC-3", "status": "draft"},
+			},
+			wantTags: []any{"iso", "soc2"},
+		},
+		{
+			name: "merge by key",
+			opts: []convert.MergeOption{convert.WithMergeKey("name")},
+			wantControls: []any{
+				map[string]any{"name": "AC-1", "status": "draft", "evidence": []any{"doc-1"}},
+				map[string]any{"name": "AC-2", "status": "approved"},
+				map[string]any{"name": "AC-3", "status": "draft"},
+			},
+			wantTags: []any{"iso", "soc2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := newMergeBase()
+
+			merged := convert.Merge(base, override, tt.opts...)
+
+			assert.Equal(t, map[string]any{"team": "grc", "email": "audit@example.com"}, merged["owner"])
+			assert.Equal(t, map[string]any{"env": "prod", "tier": "gold"}, merged["labels"])
+			assert.Contains(t, merged, "title")
+			assert.Nil(t, merged["title"])
+			assert.Equal(t, tt.wantControls, merged["controls"])
+			assert.Equal(t, tt.wantTags, merged["tags"])
+
+			assert.Equal(t, newMergeBase(), base, "dst must not be modified")
+		})
+	}
+}
+
+func TestMerge_DoesNotAlias(t *testing.T) {
+	base := newMergeBase()
+	src := map[string]any{"owner": map[string]any{"nested": map[string]any{"k": "v"}}}
+
+	merged := convert.Merge(base, src, convert.WithMergeKey("name"))
+
+	require.NoError(t, convert.Set(merged, "owner.nested.k", "changed"))
+	require.NoError(t, convert.Set(merged, "controls[0].evidence[0]", "changed"))
+
+	assert.Equal(t, "v", src["owner"].(map[string]any)["nested"].(map[string]any)["k"])
+	assert.Equal(t, newMergeBase(), base)
+}
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7386, Appendix A
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			target, patch := decodeDict(t, tt.target), decodeDict(t, tt.patch)
+
+			got := convert.MergePatch(target, patch)
+			assert.Equal(t, decodeDict(t, tt.want), got)
+			assert.Equal(t, decodeDict(t, tt.target), target, "target must not be modified")
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	from := map[string]any{
+		"status":   "draft",
+		"owner":    "alice",
+		"tags":     []string{"a"},
+		"controls": map[string]any{"AC-1": map[string]any{"status": "draft", "score": 1}},
+	}
+	to := map[string]any{
+		"status":   "approved",
+		"tags":     []any{"a"},
+		"controls": map[string]any{"AC-1": map[string]any{"status": "draft", "score": float64(2)}},
+	}
+
+	patch := convert.CreateMergePatch(from, to)
+	assert.Equal(t, map[string]any{
+		"status":   "approved",
+		"owner":    nil,
+		"controls": map[string]any{"AC-1": map[string]any{"score": float64(2)}},
+	}, patch)
+
+	assert.True(t, convert.Equal(to, convert.MergePatch(from, patch)))
+}
+
+func decodeDict(t *testing.T, s string) map[string]any {
+	t.Helper()
+
+	var d map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s), &d))
+
+	return d
+}
@@ -0,0 +1,320 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+var (
+	// ErrCycleDetected indicates a value that refers back to itself
+	ErrCycleDetected = errors.New("cannot convert to dict: cycle detected")
+
+	// ErrMaxDepthExceeded indicates a value nested deeper than the configured maximum
+	ErrMaxDepthExceeded = errors.New("cannot convert to dict: maximum depth exceeded")
+)
+
+// CyclePolicy controls how JSONToDict handles values that refer back to one
+// of their ancestors, such as parent pointers in a tree.
+type CyclePolicy int
+
+const (
+	// CycleError fails the conversion with ErrCycleDetected.
+	CycleError CyclePolicy = iota
+
+	// CycleMarker replaces the repeated value with a marker. See
+	// WithCycleMarker.
+	CycleMarker
+
+	// CycleSkip omits the repeated value: struct fields and map entries are
+	// left out and slice elements are removed.
+	CycleSkip
+)
+
+// DefaultCycleMarker is the value used by CycleMarker unless WithCycleMarker
+// is given.
+const DefaultCycleMarker = "[circular]"
+
+// DefaultRedactMask replaces the values of fields tagged redact:"true"
+// unless WithRedactMask is given.
+const DefaultRedactMask = "[REDACTED]"
+
+// startDetectingCyclesAfter is the pointer depth after which CycleError
+// starts tracking visited values. Tracking every value costs a map lookup
+// per pointer, so shallow values are converted without it, as encoding/json
+// does.
+const startDetectingCyclesAfter = 1000
+
+// DictOption configures JSONToDict and JSONToDictSlice.
+type DictOption func(*dictOptions)
+
+type dictOptions struct {
+	cycles       CyclePolicy
+	marker       any
+	maxDepth     int
+	redactMask   any
+	include      [][]pathSegment
+	exclude      [][]pathSegment
+	transformers map[reflect.Type]func(reflect.Value) (any, error)
+
+	// err is the first invalid option
+	err error
+}
+
+// defaultDictOptions are used when no options are given.
+var defaultDictOptions = dictOptions{marker: DefaultCycleMarker, redactMask: DefaultRedactMask}
+
+// WithCyclePolicy sets how values that refer back to one of their ancestors
+// are handled. The default is CycleError.
+func WithCyclePolicy(policy CyclePolicy) DictOption {
+	return func(o *dictOptions) {
+		o.cycles = policy
+	}
+}
+
+// WithCycleMarker replaces values that refer back to one of their ancestors
+// with marker. It implies CycleMarker.
+func WithCycleMarker(marker any) DictOption {
+	return func(o *dictOptions) {
+		o.cycles = CycleMarker
+		o.marker = marker
+	}
+}
+
+// WithMaxDepth limits the nesting of dicts and slices in the result to depth
+// levels, counting the returned dict as the first level. Deeper values fail
+// the conversion with ErrMaxDepthExceeded. Zero or a negative depth means no
+// limit.
+func WithMaxDepth(depth int) DictOption {
+	return func(o *dictOptions) {
+		o.maxDepth = max(depth, 0)
+	}
+}
+
+// WithInclude limits the result to the values at paths and their ancestors.
+// Paths use the syntax of Get, where an unquoted * matches any key and [*]
+// any slice index, e.g. "spec.items[*].name". Paths start at the returned
+// dict, or at the returned slice for JSONToDictSlice. Multiple calls add
+// paths.
+func WithInclude(paths ...string) DictOption {
+	return func(o *dictOptions) {
+		o.include = appendPatterns(o, o.include, paths)
+	}
+}
+
+// WithExclude omits the values at paths, which use the syntax of
+// WithInclude. Exclusion takes precedence over inclusion. Multiple calls add
+// paths.
+func WithExclude(paths ...string) DictOption {
+	return func(o *dictOptions) {
+		o.exclude = appendPatterns(o, o.exclude, paths)
+	}
+}
+
+// appendPatterns parses paths and appends them to patterns, recording the
+// first invalid path in o.
+func appendPatterns(o *dictOptions, patterns [][]pathSegment, paths []string) [][]pathSegment {
+	patterns = slices.Clip(patterns)
+
+	for _, path := range paths {
+		segs, err := parsePattern(path)
+		if err != nil {
+			o.err = cmp.Or(o.err, err)
+			continue
+		}
+		patterns = append(patterns, segs)
+	}
+
+	return patterns
+}
+
+// WithRedactMask sets the value that replaces fields tagged redact:"true".
+// The default is DefaultRedactMask.
+func WithRedactMask(mask any) DictOption {
+	return func(o *dictOptions) {
+		o.redactMask = mask
+	}
+}
+
+// WithTransformer converts values of type T with fn instead of the default
+// conversion, including values that non-nil *T pointers point to. The
+// result of fn is used as is. T is matched against the declared type of
+// each value, so a transformer for an interface type such as fmt.Stringer
+// applies to struct fields, map values and slice elements declared with that
+// type, not to all types implementing it. Later transformers for the same
+// type replace earlier ones.
+func WithTransformer[T any](fn func(T) (any, error)) DictOption {
+	t := reflect.TypeFor[T]()
+
+	return func(o *dictOptions) {
+		o.transformers = maps.Clone(o.transformers)
+		if o.transformers == nil {
+			o.transformers = make(map[reflect.Type]func(reflect.Value) (any, error))
+		}
+
+		o.transformers[t] = func(v reflect.Value) (any, error) {
+			x, _ := v.Interface().(T)
+			return fn(x)
+		}
+	}
+}
+
+// encodeState holds the state of a single conversion.
+type encodeState struct {
+	*dictOptions
+
+	// depth is the number of dicts and slices entered.
+	depth int
+
+	// refLevel is the number of pointers, maps and slices entered. Values
+	// are tracked in refSeen once refLevel exceeds cycleAfter.
+	refLevel   int
+	cycleAfter int
+	refSeen    map[refKey]struct{}
+
+	// path is the location of the value being converted. It is only tracked
+	// with WithInclude or WithExclude.
+	path []pathSegment
+}
+
+// refKey identifies a pointer, map or slice on the current path. Slices are
+// keyed by length as well, since subslices share their data pointer.
+type refKey struct {
+	ptr unsafe.Pointer
+	len int
+	typ reflect.Type
+}
+
+// errSkipValue is returned by encoders to omit a value under CycleSkip. It is
+// handled by the enclosing struct, map or slice encoder.
+var errSkipValue = errors.New("skip value")
+
+var encodeStatePool sync.Pool
+
+// newDictOptions applies opts to the default options.
+func newDictOptions(opts []DictOption) *dictOptions {
+	if len(opts) == 0 {
+		return &defaultDictOptions
+	}
+
+	o := defaultDictOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &o
+}
+
+// newEncodeState returns a state for a conversion with o. It must be
+// released with release when the conversion is done.
+func newEncodeState(o *dictOptions) *encodeState {
+	e, _ := encodeStatePool.Get().(*encodeState)
+	if e == nil {
+		e = &encodeState{}
+	}
+
+	e.dictOptions = o
+
+	e.cycleAfter = startDetectingCyclesAfter
+	if e.cycles != CycleError {
+		e.cycleAfter = 0
+	}
+
+	return e
+}
+
+// release returns e to the pool.
+func (e *encodeState) release() {
+	e.dictOptions = nil
+	e.depth = 0
+	e.refLevel = 0
+	clear(e.refSeen)
+	e.path = e.path[:0]
+	encodeStatePool.Put(e)
+}
+
+// enterRef records that the pointer, map or slice v is being converted. It
+// reports true if v is already being converted further up, in which case
+// leaveRef must not be called.
+func (e *encodeState) enterRef(v reflect.Value) (refKey, bool) {
+	e.refLevel++
+	if e.refLevel <= e.cycleAfter {
+		return refKey{}, false
+	}
+
+	key := refKey{ptr: v.UnsafePointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+
+	if _, ok := e.refSeen[key]; ok {
+		e.refLevel--
+		return key, true
+	}
+
+	if e.refSeen == nil {
+		e.refSeen = make(map[refKey]struct{})
+	}
+	e.refSeen[key] = struct{}{}
+
+	return key, false
+}
+
+// leaveRef reverts enterRef once v has been converted.
+func (e *encodeState) leaveRef(key refKey) {
+	if e.refLevel > e.cycleAfter {
+		delete(e.refSeen, key)
+	}
+	e.refLevel--
+}
+
+// cycle returns the result for a value that refers back to an ancestor.
+func (e *encodeState) cycle(v reflect.Value) (any, error) {
+	switch e.cycles {
+	case CycleMarker:
+		return e.marker, nil
+	case CycleSkip:
+		return nil, errSkipValue
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrCycleDetected, v.Type())
+}
+
+// descend enters levels nested dicts or slices. It fails if the result
+// would exceed the maximum depth; ascend must only be called on success.
+func (e *encodeState) descend(levels int) error {
+	if e.maxDepth > 0 && e.depth+levels > e.maxDepth {
+		return fmt.Errorf("%w: limit is %d", ErrMaxDepthExceeded, e.maxDepth)
+	}
+	e.depth += levels
+
+	return nil
+}
+
+// ascend reverts descend.
+func (e *encodeState) ascend(levels int) {
+	e.depth -= levels
+}
+
+// dynamicBudget returns the number of levels dynamicNeedsConversion may
+// scan before the value has to be converted with cycle and depth checks.
+func (e *encodeState) dynamicBudget() int {
+	budget := startDetectingCyclesAfter
+	if e.cycles != CycleError {
+		// any nested map or slice may close a cycle
+		budget = 1
+	}
+	if e.maxDepth > 0 {
+		budget = min(budget, e.maxDepth-e.depth)
+	}
+
+	return budget
+}
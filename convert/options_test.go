@@ -0,0 +1,190 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type controlNode struct {
+	Name     string         `json:"name"`
+	Parent   *controlNode   `json:"parent,omitempty"`
+	Children []*controlNode `json:"children,omitempty"`
+}
+
+// newControlTree returns a root with two children pointing back to it.
+func newControlTree() *controlNode {
+	root := &controlNode{Name: "root"}
+	for _, name := range []string{"a", "b"} {
+		root.Children = append(root.Children, &controlNode{Name: name, Parent: root})
+	}
+
+	return root
+}
+
+func TestJSONToDict_CyclePolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []convert.DictOption
+		want map[string]any
+	}{
+		{
+			name: "marker",
+			opts: []convert.DictOption{convert.WithCyclePolicy(convert.CycleMarker)},
+			want: map[string]any{"name": "root", "children": []any{
+				map[string]any{"name": "a", "parent": convert.DefaultCycleMarker},
+				map[string]any{"name": "b", "parent": convert.DefaultCycleMarker},
+			}},
+		},
+		{
+			name: "custom marker",
+			opts: []convert.DictOption{convert.WithCycleMarker(map[string]any{"$ref": "#"})},
+			want: map[string]any{"name": "root", "children": []any{
+				map[string]any{"name": "a", "parent": map[string]any{"$ref": "#"}},
+				map[string]any{"name": "b", "parent": map[string]any{"$ref": "#"}},
+			}},
+		},
+		{
+			name: "skip",
+			opts: []convert.DictOption{convert.WithCyclePolicy(convert.CycleSkip)},
+			want: map[string]any{"name": "root", "children": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := convert.JSONToDict(newControlTree(), tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestJSONToDict_CycleError(t *testing.T) {
+	_, err := convert.JSONToDict(newControlTree())
+	require.ErrorIs(t, err, convert.ErrCycleDetected)
+	assert.Contains(t, err.Error(), "children")
+	assert.Contains(t, err.Error(), "parent")
+}
+
+func TestJSONToDict_CycleDynamic(t *testing.T) {
+	self := map[string]any{"name": "self"}
+	self["self"] = self
+
+	list := []any{"a", nil}
+	list[1] = list
+
+	in := map[string]any{"map": self, "list": list}
+
+	// without options a map[string]any is returned as is
+	result, err := convert.JSONToDict(in)
+	require.NoError(t, err)
+	assert.Equal(t, reflect.ValueOf(in).Pointer(), reflect.ValueOf(result).Pointer())
+
+	_, err = convert.JSONToDict(in, convert.WithCyclePolicy(convert.CycleError))
+	assert.ErrorIs(t, err, convert.ErrCycleDetected)
+
+	_, err = convert.JSONToDict(struct {
+		In map[string]any `json:"in"`
+	}{In: in})
+	assert.ErrorIs(t, err, convert.ErrCycleDetected, "nested dicts are checked by default")
+
+	result, err = convert.JSONToDict(in, convert.WithCyclePolicy(convert.CycleMarker))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"map":  map[string]any{"name": "self", "self": convert.DefaultCycleMarker},
+		"list": []any{"a", convert.DefaultCycleMarker},
+	}, result)
+
+	result, err = convert.JSONToDict(in, convert.WithCyclePolicy(convert.CycleSkip))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"map":  map[string]any{"name": "self"},
+		"list": []any{"a"},
+	}, result)
+}
+
+func TestJSONToDict_SharedValuesAreNotCycles(t *testing.T) {
+	shared := &controlNode{Name: "shared"}
+	tags := map[string]any{"env": "prod"}
+
+	in := struct {
+		A    *controlNode   `json:"a"`
+		B    *controlNode   `json:"b"`
+		List []*controlNode `json:"list"`
+		Tags []any          `json:"tags"`
+	}{A: shared, B: shared, List: []*controlNode{shared, shared}, Tags: []any{tags, tags}}
+
+	node := map[string]any{"name": "shared"}
+	want := map[string]any{
+		"a":    node,
+		"b":    node,
+		"list": []any{node, node},
+		"tags": []any{tags, tags},
+	}
+
+	for _, policy := range []convert.CyclePolicy{convert.CycleError, convert.CycleMarker, convert.CycleSkip} {
+		result, err := convert.JSONToDict(in, convert.WithCyclePolicy(policy))
+		require.NoError(t, err)
+		assert.Equal(t, want, result)
+	}
+}
+
+func TestJSONToDict_MaxDepth(t *testing.T) {
+	type leaf struct {
+		Labels map[string][]string `json:"labels"`
+	}
+
+	type branch struct {
+		Leaf leaf `json:"leaf"`
+	}
+
+	in := struct {
+		Branch branch `json:"branch"`
+	}{Branch: branch{Leaf: leaf{Labels: map[string][]string{"env": {"prod"}}}}}
+
+	// dict, branch, leaf, labels and the []string make five levels
+	result, err := convert.JSONToDict(in, convert.WithMaxDepth(5))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"env": {"prod"}},
+		result["branch"].(map[string]any)["leaf"].(map[string]any)["labels"])
+
+	_, err = convert.JSONToDict(in, convert.WithMaxDepth(4))
+	require.ErrorIs(t, err, convert.ErrMaxDepthExceeded)
+	assert.Contains(t, err.Error(), "branch")
+
+	_, err = convert.JSONToDict(in, convert.WithMaxDepth(0))
+	assert.NoError(t, err)
+
+	dict := map[string]any{"a": map[string]any{"b": []any{1}}}
+
+	_, err = convert.JSONToDict(dict, convert.WithMaxDepth(3))
+	assert.NoError(t, err)
+
+	_, err = convert.JSONToDict(dict, convert.WithMaxDepth(2))
+	assert.ErrorIs(t, err, convert.ErrMaxDepthExceeded)
+}
+
+func TestJSONToDictSlice_Options(t *testing.T) {
+	result, err := convert.JSONToDictSlice([]*controlNode{newControlTree().Children[0]},
+		convert.WithCyclePolicy(convert.CycleSkip))
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{
+		"name": "a",
+		"parent": map[string]any{"name": "root", "children": []any{
+			map[string]any{"name": "b"},
+		}},
+	}}, result)
+
+	_, err = convert.JSONToDictSlice([]any{map[string]any{"a": 1}}, convert.WithMaxDepth(1))
+	assert.ErrorIs(t, err, convert.ErrMaxDepthExceeded)
+}
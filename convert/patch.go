@@ -0,0 +1,370 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch indicates a malformed JSON Patch operation
+	ErrInvalidPatch = errors.New("invalid patch")
+
+	// ErrPatchTestFailed indicates a JSON Patch test operation did not match
+	ErrPatchTestFailed = errors.New("patch test failed")
+)
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// JSON Patch operations as defined by RFC 6902.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// PatchOperation is a single RFC 6902 JSON Patch operation.
+//
+// Path and From are RFC 6901 JSON Pointers such as "/controls/0/status".
+type PatchOperation struct {
+	// Op is one of OpAdd, OpRemove, OpReplace, OpMove, OpCopy or OpTest.
+	Op string `json:"op"`
+
+	// Path is the target location of the operation.
+	Path string `json:"path"`
+
+	// From is the source location of move and copy operations.
+	From string `json:"from,omitempty"`
+
+	// Value is the value of add, replace and test operations.
+	Value any `json:"value,omitempty"`
+}
+
+// MarshalJSON encodes the operation, including a null value for add,
+// replace and test operations.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		return json.Marshal(struct {
+			Op    string `json:"op"`
+			Path  string `json:"path"`
+			Value any    `json:"value"`
+		}{op.Op, op.Path, op.Value})
+	}
+
+	return json.Marshal(struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+		From string `json:"from,omitempty"`
+	}{op.Op, op.Path, op.From})
+}
+
+// Patch is an RFC 6902 JSON Patch document. It can be marshaled to and
+// unmarshaled from JSON directly.
+type Patch []PatchOperation
+
+// CreatePatch returns the RFC 6902 JSON Patch that transforms from into to.
+// It contains the changes reported by Diff.
+//
+// # Examples
+//
+//	from := map[string]any{"controls": []any{map[string]any{"id": "AC-1", "status": "draft"}}}
+//	to := map[string]any{"controls": []any{map[string]any{"id": "AC-1", "status": "approved"}}}
+//
+//	patch := CreatePatch(from, to)
+//	// patch: Patch{{Op: OpReplace, Path: "/controls/0/status", Value: "approved"}}
+func CreatePatch(from, to map[string]any) Patch {
+	var patch Patch
+
+	diffValues(nil, from, to, func(t ChangeType, segs []pathSegment, _, to any) {
+		op := PatchOperation{Op: string(t), Path: formatPointer(segs)}
+		if t != ChangeRemove {
+			op.Value = cloneValue(to)
+		}
+		patch = append(patch, op)
+	})
+
+	return patch
+}
+
+// Apply applies the patch to d and returns the result as a new dict. d is not
+// modified. The patch is applied atomically: if any operation fails, an error
+// is returned and no result.
+//
+// # Errors
+//
+// Errors name the failing operation and wrap ErrInvalidPatch for unknown
+// operations and malformed pointers, ErrPatchTestFailed for failed test
+// operations, ErrPathNotFound for missing locations and ErrTypeMismatch for
+// locations that cannot be traversed.
+//
+// # Examples
+//
+//	var patch Patch
+//	err := json.Unmarshal([]byte(`[
+//		{"op": "test", "path": "/status", "value": "draft"},
+//		{"op": "replace", "path": "/status", "value": "approved"},
+//		{"op": "add", "path": "/reviewers/-", "value": "bob"}
+//	]`), &patch)
+//
+//	result, err := patch.Apply(map[string]any{"status": "draft", "reviewers": []any{"alice"}})
+//	// result: map[string]any{"status": "approved", "reviewers": []any{"alice", "bob"}}
+func (p Patch) Apply(d map[string]any) (map[string]any, error) {
+	doc := cloneDict(d)
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	for i, op := range p {
+		var err error
+		if doc, err = applyOperation(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func applyOperation(doc map[string]any, op PatchOperation) (map[string]any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return addAt(doc, path, cloneValue(op.Value))
+	case OpRemove:
+		return removeAt(doc, path)
+	case OpReplace:
+		return replaceAt(doc, path, cloneValue(op.Value))
+	case OpMove:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+			return nil, fmt.Errorf("%w: cannot move %q into its own child", ErrInvalidPatch, op.From)
+		}
+
+		v, err := getAt(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = removeAt(doc, from); err != nil {
+			return nil, err
+		}
+		return addAt(doc, path, v)
+	case OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := getAt(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addAt(doc, path, cloneValue(v))
+	case OpTest:
+		v, err := getAt(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalValues(v, op.Value) {
+			return nil, fmt.Errorf("%w: got %v, want %v", ErrPatchTestFailed, v, op.Value)
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+}
+
+// getAt returns the value at the pointer path within doc.
+func getAt(doc map[string]any, path []string) (any, error) {
+	var v any = doc
+
+	for _, token := range path {
+		seg, err := pointerSegment(v, token)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = child(v, seg); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// addAt adds value at path, inserting into slices.
+func addAt(doc map[string]any, path []string, value any) (map[string]any, error) {
+	return modifyDoc(doc, path, value, func(v any, seg pathSegment) (any, error) {
+		if !seg.isIndex {
+			return withChild(v, seg, value)
+		}
+		return insertChild(v, seg, value)
+	})
+}
+
+// removeAt removes the value at path.
+func removeAt(doc map[string]any, path []string) (map[string]any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the root", ErrInvalidPatch)
+	}
+
+	return modifyDoc(doc, path, nil, withoutChild)
+}
+
+// replaceAt replaces the existing value at path.
+func replaceAt(doc map[string]any, path []string, value any) (map[string]any, error) {
+	return modifyDoc(doc, path, value, func(v any, seg pathSegment) (any, error) {
+		if _, err := child(v, seg); err != nil {
+			return nil, err
+		}
+		return withChild(v, seg, value)
+	})
+}
+
+// modifyDoc applies fn to the container of the last token of path. An empty
+// path replaces the whole document with root, which must be a dict.
+func modifyDoc(doc map[string]any, path []string, root any, fn func(any, pathSegment) (any, error)) (map[string]any, error) {
+	if len(path) == 0 {
+		d, ok := asDict(root)
+		if !ok {
+			return nil, fmt.Errorf("%w: root must be a dict, got %T", ErrTypeMismatch, root)
+		}
+		return d, nil
+	}
+
+	v, err := modifyAt(doc, path, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(map[string]any), nil
+}
+
+func modifyAt(v any, path []string, fn func(any, pathSegment) (any, error)) (any, error) {
+	seg, err := pointerSegment(v, path[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) == 1 {
+		return fn(v, seg)
+	}
+
+	c, err := child(v, seg)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, err = modifyAt(c, path[1:], fn); err != nil {
+		return nil, err
+	}
+
+	return withChild(v, seg, c)
+}
+
+// insertChild inserts c into the slice v at seg, shifting later elements.
+func insertChild(v any, seg pathSegment, c any) (any, error) {
+	if s, ok := v.([]any); ok {
+		if seg.index > len(s) {
+			return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, len(s))
+		}
+		return slices.Insert(s, seg.index, c), nil
+	}
+
+	if v == nil {
+		return nil, fmt.Errorf("%w: parent is null", ErrPathNotFound)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%w: cannot insert into %T", ErrTypeMismatch, v)
+	}
+	if seg.index > rv.Len() {
+		return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, rv.Len())
+	}
+
+	elem, err := assignable(c, rv.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	out := reflect.MakeSlice(rv.Type(), 0, rv.Len()+1)
+	out = reflect.AppendSlice(out, rv.Slice(0, seg.index))
+	out = reflect.Append(out, elem)
+	out = reflect.AppendSlice(out, rv.Slice(seg.index, rv.Len()))
+
+	return out.Interface(), nil
+}
+
+// pointerSegment interprets a JSON Pointer token for the container v. Tokens
+// address slice elements by index, with "-" referring to the end of the
+// slice, and map entries by key.
+func pointerSegment(v any, token string) (pathSegment, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return pathSegment{key: token}, nil
+	}
+
+	if token == "-" {
+		return pathSegment{index: rv.Len(), isIndex: true}, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') || token[0] == '+' {
+		return pathSegment{}, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	return pathSegment{index: index, isIndex: true}, nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+
+	return tokens, nil
+}
+
+// formatPointer returns the RFC 6901 JSON Pointer for segs.
+func formatPointer(segs []pathSegment) string {
+	var b strings.Builder
+
+	for _, seg := range segs {
+		b.WriteByte('/')
+		if seg.isIndex {
+			b.WriteString(strconv.Itoa(seg.index))
+		} else {
+			b.WriteString(pointerEscaper.Replace(seg.key))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodePatch(t *testing.T, s string) convert.Patch {
+	t.Helper()
+
+	var patch convert.Patch
+	require.NoError(t, json.Unmarshal([]byte(s), &patch))
+
+	return patch
+}
+
+func TestPatch_Apply(t *testing.T) {
+	// Test cases from RFC 6902, Appendix A
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"escaped pointer", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"replace","path":"/~1","value":1}]`, `{"/":1,"~1":10}`},
+		{"add array value", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeDict(t, tt.doc)
+
+			got, err := decodePatch(t, tt.patch).Apply(doc)
+			require.NoError(t, err)
+			assert.Equal(t, decodeDict(t, tt.want), got)
+			assert.Equal(t, decodeDict(t, tt.doc), doc, "document must not be modified")
+		})
+	}
+}
+
+func TestPatch_ApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		wantErr error
+	}{
+		{"test failed", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, convert.ErrPatchTestFailed},
+		{"add to nonexistent target", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, convert.ErrPathNotFound},
+		{"remove missing", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, convert.ErrPathNotFound},
+		{"replace missing", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, convert.ErrPathNotFound},
+		{"index out of range", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/5","value":1}]`, convert.ErrPathNotFound},
+		{"invalid index", `{"foo":["bar"]}`, `[{"op":"replace","path":"/foo/01","value":1}]`, convert.ErrInvalidPatch},
+		{"add under null", `{"a":null}`, `[{"op":"add","path":"/a/b","value":1}]`, convert.ErrPathNotFound},
+		{"add index under null", `{"a":null}`, `[{"op":"add","path":"/a/0","value":1}]`, convert.ErrPathNotFound},
+		{"replace under null", `{"a":null}`, `[{"op":"replace","path":"/a/b","value":1}]`, convert.ErrPathNotFound},
+		{"remove under null", `{"a":null}`, `[{"op":"remove","path":"/a/b"}]`, convert.ErrPathNotFound},
+		{"add below null", `{"a":null}`, `[{"op":"add","path":"/a/b/c","value":1}]`, convert.ErrPathNotFound},
+		{"traverse scalar", `{"foo":"bar"}`, `[{"op":"add","path":"/foo/x","value":1}]`, convert.ErrTypeMismatch},
+		{"unknown op", `{}`, `[{"op":"frobnicate","path":"/a"}]`, convert.ErrInvalidPatch},
+		{"invalid pointer", `{}`, `[{"op":"add","path":"a","value":1}]`, convert.ErrInvalidPatch},
+		{"move into child", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, convert.ErrInvalidPatch},
+		{"remove root", `{}`, `[{"op":"remove","path":""}]`, convert.ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeDict(t, tt.doc)
+			patch := decodePatch(t, tt.patch)
+
+			got, err := append(convert.Patch{{Op: convert.OpAdd, Path: "/applied", Value: true}}, patch...).Apply(doc)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), "operation 1")
+			assert.Nil(t, got)
+			assert.NotContains(t, doc, "applied", "failed patches must not modify the document")
+		})
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	from := decodeDict(t, `{
+		"control": {"status": "draft", "owners": ["alice", "bob", "carol"], "a/b": 1},
+		"removed": true
+	}`)
+	to := decodeDict(t, `{
+		"control": {"status": "approved", "owners": ["alice"], "a/b": 2, "note": null},
+		"added": {"x": 1}
+	}`)
+
+	patch := convert.CreatePatch(from, to)
+	assert.Equal(t, convert.Patch{
+		{Op: convert.OpAdd, Path: "/added", Value: map[string]any{"x": float64(1)}},
+		{Op: convert.OpReplace, Path: "/control/a~1b", Value: float64(2)},
+		{Op: convert.OpAdd, Path: "/control/note", Value: nil},
+		{Op: convert.OpRemove, Path: "/control/owners/2"},
+		{Op: convert.OpRemove, Path: "/control/owners/1"},
+		{Op: convert.OpReplace, Path: "/control/status", Value: "approved"},
+		{Op: convert.OpRemove, Path: "/removed"},
+	}, patch)
+
+	got, err := patch.Apply(from)
+	require.NoError(t, err)
+	assert.Equal(t, to, got)
+
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `{"op":"add","path":"/control/note","value":null}`)
+	assert.Contains(t, string(data), `{"op":"remove","path":"/removed"}`)
+
+	assert.Equal(t, patch, decodePatch(t, string(data)))
+}
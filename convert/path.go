@@ -0,0 +1,599 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPath indicates a path expression cannot be parsed
+	ErrInvalidPath = errors.New("invalid path")
+
+	// ErrPathNotFound indicates a key or index along a path does not exist
+	ErrPathNotFound = errors.New("path not found")
+)
+
+// PathError describes a failure to resolve a path within a dictionary.
+//
+// Path is the prefix of the requested path at which resolution stopped. For
+// ErrPathNotFound it names the missing key or index, e.g. "spec.items[3]";
+// for ErrTypeMismatch it names the value that is not a map or slice.
+type PathError struct {
+	// Path is the location within the dictionary where resolution failed.
+	Path string
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message including the path.
+func (e *PathError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "<root>"
+	}
+
+	return fmt.Sprintf("%s: %v", path, e.Err)
+}
+
+// Unwrap returns the underlying reason for errors.Is and errors.As.
+func (e *PathError) Unwrap() error { return e.Err }
+
+// pathSegment is a map key or a slice index within a path.
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool // matches any key or index, only in patterns
+}
+
+// Get returns the value at path within d.
+//
+// # Path Syntax
+//
+// Paths use dot notation for map keys and brackets for slice indexes, as used
+// by FieldError. Keys containing dots or brackets can be quoted in brackets:
+//
+//	spec.items[2].name
+//	labels["app.kubernetes.io/name"]
+//
+// Maps with string keys and slices of any type are traversed, so values
+// preserved by JSONToDict such as map[string]string or []string can be
+// addressed as well.
+//
+// # Errors
+//
+// Returns ErrInvalidPath if path cannot be parsed. Other failures are
+// returned as *PathError wrapping ErrPathNotFound for missing keys, out of
+// range indexes and null values, or ErrTypeMismatch for values that cannot
+// be traversed.
+//
+// # Examples
+//
+//	dict := map[string]any{"spec": map[string]any{"items": []any{
+//		map[string]any{"name": "a"},
+//	}}}
+//
+//	name, err := Get(dict, "spec.items[0].name")
+//	// name: "a"
+//
+//	_, err = Get(dict, "spec.items[1].name")
+//	// err: spec.items[1]: path not found: index out of range with length 1
+func Get(d map[string]any, path string) (any, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any = d
+	for i, seg := range segs {
+		if v, err = child(v, seg); err != nil {
+			return nil, pathError(segs, i, err)
+		}
+	}
+
+	return v, nil
+}
+
+// Has reports whether a value exists at path within d. Invalid paths are
+// reported as missing.
+func Has(d map[string]any, path string) bool {
+	_, err := Get(d, path)
+	return err == nil
+}
+
+// Set assigns value at path within d.
+//
+// Missing map keys along the path are created as map[string]any, missing
+// slices as []any. An index equal to the length of a slice appends to it.
+// Slices and maps of other types are modified in place if value is
+// assignable to their element type.
+//
+// # Errors
+//
+// Returns ErrInvalidPath if path cannot be parsed. Other failures are
+// returned as *PathError wrapping ErrPathNotFound for indexes beyond the end
+// of a slice, or ErrTypeMismatch for values that cannot be traversed or
+// elements value cannot be assigned to.
+//
+// # Examples
+//
+//	dict := map[string]any{}
+//	err := Set(dict, "spec.items[0].name", "a")
+//	// dict: map[string]any{"spec": map[string]any{"items": []any{
+//	//   map[string]any{"name": "a"},
+//	// }}}
+func Set(d map[string]any, path string, value any) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	if d == nil {
+		return &PathError{Err: fmt.Errorf("%w: dict is nil", ErrTypeMismatch)}
+	}
+
+	_, err = setIn(d, segs, 0, value)
+
+	return err
+}
+
+// Delete removes the value at path from d. Slice elements after a deleted
+// index move up by one.
+//
+// # Errors
+//
+// Returns ErrInvalidPath if path cannot be parsed. Other failures are
+// returned as *PathError wrapping ErrPathNotFound if the value does not
+// exist, or ErrTypeMismatch for values that cannot be traversed.
+func Delete(d map[string]any, path string) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = deleteIn(d, segs, 0)
+
+	return err
+}
+
+// GetAs returns the value at path within d converted to T.
+//
+// Values are converted the way DictToStruct converts fields: numbers are
+// coerced between numeric types as long as they fit, nested maps become
+// structs and slices become typed slices.
+//
+// # Errors
+//
+// Returns the errors of Get. Values that cannot be converted, including
+// null, are returned as *FieldError carrying the path.
+//
+// # Examples
+//
+//	dict := map[string]any{"limits": map[string]any{"cpu": float64(2)}}
+//
+//	cpu, err := GetAs[int](dict, "limits.cpu")
+//	// cpu: 2
+//
+//	_, err = GetAs[bool](dict, "limits.cpu")
+//	// err: limits.cpu: cannot convert to bool: type mismatch: got float64
+func GetAs[T any](d map[string]any, path string) (T, error) {
+	var result T
+
+	v, err := Get(d, path)
+	if err != nil {
+		return result, err
+	}
+
+	rv := reflect.ValueOf(&result).Elem()
+	if v == nil {
+		return result, mismatch(path, rv.Type(), v)
+	}
+
+	if err := (decoder{}).decodeValue(path, v, rv); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, nil
+}
+
+// GetString returns the string at path within d. See GetAs.
+func GetString(d map[string]any, path string) (string, error) {
+	return GetAs[string](d, path)
+}
+
+// GetInt returns the integer at path within d. Floating point numbers must
+// be integral. See GetAs.
+func GetInt(d map[string]any, path string) (int, error) {
+	return GetAs[int](d, path)
+}
+
+// GetFloat returns the number at path within d as float64. See GetAs.
+func GetFloat(d map[string]any, path string) (float64, error) {
+	return GetAs[float64](d, path)
+}
+
+// GetBool returns the boolean at path within d. See GetAs.
+func GetBool(d map[string]any, path string) (bool, error) {
+	return GetAs[bool](d, path)
+}
+
+// GetSlice returns the slice at path within d as []any. Typed slices are
+// copied. See GetAs.
+func GetSlice(d map[string]any, path string) ([]any, error) {
+	return GetAs[[]any](d, path)
+}
+
+// GetMap returns the map at path within d as map[string]any. Typed maps are
+// copied. See GetAs.
+func GetMap(d map[string]any, path string) (map[string]any, error) {
+	return GetAs[map[string]any](d, path)
+}
+
+// parsePath splits a path expression into its segments.
+func parsePath(path string) ([]pathSegment, error) {
+	return parseSegments(path, false)
+}
+
+// parsePattern parses a path that may contain wildcards: an unquoted * key
+// matches any key and [*] matches any index.
+func parsePattern(pattern string) ([]pathSegment, error) {
+	return parseSegments(pattern, true)
+}
+
+func parseSegments(path string, wildcards bool) ([]pathSegment, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidPath, path, reason)
+	}
+
+	if path == "" {
+		return nil, invalid("empty path")
+	}
+
+	var segs []pathSegment
+
+	s := path
+	for s != "" {
+		switch {
+		case s[0] == '[' && len(s) > 1 && s[1] == '"':
+			quoted, err := strconv.QuotedPrefix(s[1:])
+			if err != nil {
+				return nil, invalid("unterminated quoted key")
+			}
+			key, _ := strconv.Unquote(quoted)
+
+			s = s[1+len(quoted):]
+			if !strings.HasPrefix(s, "]") {
+				return nil, invalid("missing ] after quoted key")
+			}
+			s = s[1:]
+
+			segs = append(segs, pathSegment{key: key})
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, invalid("missing ]")
+			}
+
+			if wildcards && s[1:end] == "*" {
+				s = s[end+1:]
+				segs = append(segs, pathSegment{isIndex: true, wildcard: true})
+				continue
+			}
+
+			index, err := strconv.Atoi(s[1:end])
+			if err != nil || index < 0 {
+				return nil, invalid("index must be a non-negative integer")
+			}
+			s = s[end+1:]
+
+			segs = append(segs, pathSegment{index: index, isIndex: true})
+		case s[0] == '.' || len(segs) == 0:
+			if s[0] == '.' {
+				if len(segs) == 0 {
+					return nil, invalid("leading .")
+				}
+				s = s[1:]
+			}
+
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, invalid("empty key")
+			}
+
+			segs = append(segs, pathSegment{key: s[:end], wildcard: wildcards && s[:end] == "*"})
+			s = s[end:]
+		default:
+			return nil, invalid("expected . or [ after " + formatPath(segs))
+		}
+	}
+
+	return segs, nil
+}
+
+// formatPath returns the path expression for segs.
+func formatPath(segs []pathSegment) string {
+	var b strings.Builder
+
+	for _, seg := range segs {
+		switch {
+		case seg.isIndex && seg.wildcard:
+			b.WriteString("[*]")
+		case seg.isIndex:
+			b.WriteString("[" + strconv.Itoa(seg.index) + "]")
+		case seg.key == "" || strings.ContainsAny(seg.key, `.[]"`):
+			b.WriteString("[" + strconv.Quote(seg.key) + "]")
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(seg.key)
+		}
+	}
+
+	return b.String()
+}
+
+// pathError wraps err from resolving segs[i]. Missing values are reported at
+// the segment, type mismatches at the container holding it.
+func pathError(segs []pathSegment, i int, err error) error {
+	if errors.Is(err, ErrPathNotFound) {
+		return &PathError{Path: formatPath(segs[:i+1]), Err: err}
+	}
+
+	return &PathError{Path: formatPath(segs[:i]), Err: err}
+}
+
+// child returns the value at seg within v.
+func child(v any, seg pathSegment) (any, error) {
+	if v == nil {
+		return nil, fmt.Errorf("%w: parent is null", ErrPathNotFound)
+	}
+
+	switch c := v.(type) {
+	case map[string]any:
+		if !seg.isIndex {
+			elem, ok := c[seg.key]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			return elem, nil
+		}
+	case []any:
+		if seg.isIndex {
+			if seg.index >= len(c) {
+				return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, len(c))
+			}
+			return c[seg.index], nil
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if err := checkContainer(rv, seg); err != nil {
+		return nil, err
+	}
+
+	if seg.isIndex {
+		if seg.index >= rv.Len() {
+			return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, rv.Len())
+		}
+		return rv.Index(seg.index).Interface(), nil
+	}
+
+	elem := rv.MapIndex(reflect.ValueOf(seg.key).Convert(rv.Type().Key()))
+	if !elem.IsValid() {
+		return nil, ErrPathNotFound
+	}
+
+	return elem.Interface(), nil
+}
+
+// withChild returns v with the value at seg replaced by c. Indexes equal to
+// the length of a slice append to it.
+func withChild(v any, seg pathSegment, c any) (any, error) {
+	switch m := v.(type) {
+	case map[string]any:
+		if !seg.isIndex {
+			if m == nil {
+				m = map[string]any{}
+			}
+			m[seg.key] = c
+			return m, nil
+		}
+	case []any:
+		if seg.isIndex {
+			switch {
+			case seg.index < len(m):
+				m[seg.index] = c
+				return m, nil
+			case seg.index == len(m):
+				return append(m, c), nil
+			}
+			return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, len(m))
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if err := checkContainer(rv, seg); err != nil {
+		return nil, err
+	}
+
+	elem, err := assignable(c, rv.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	if !seg.isIndex {
+		if rv.IsNil() {
+			rv = reflect.MakeMap(rv.Type())
+		}
+		rv.SetMapIndex(reflect.ValueOf(seg.key).Convert(rv.Type().Key()), elem)
+		return rv.Interface(), nil
+	}
+
+	switch {
+	case rv.Kind() == reflect.Slice && seg.index < rv.Len():
+		rv.Index(seg.index).Set(elem)
+		return v, nil
+	case rv.Kind() == reflect.Slice && seg.index == rv.Len():
+		return reflect.Append(rv, elem).Interface(), nil
+	case rv.Kind() == reflect.Array:
+		return nil, fmt.Errorf("%w: cannot modify array %s", ErrTypeMismatch, rv.Type())
+	}
+
+	return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, rv.Len())
+}
+
+// withoutChild returns v with the value at seg removed.
+func withoutChild(v any, seg pathSegment) (any, error) {
+	switch m := v.(type) {
+	case map[string]any:
+		if !seg.isIndex {
+			if _, ok := m[seg.key]; !ok {
+				return nil, ErrPathNotFound
+			}
+			delete(m, seg.key)
+			return m, nil
+		}
+	case []any:
+		if seg.isIndex {
+			if seg.index >= len(m) {
+				return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, len(m))
+			}
+			return slices.Delete(m, seg.index, seg.index+1), nil
+		}
+	}
+
+	if v == nil {
+		return nil, fmt.Errorf("%w: parent is null", ErrPathNotFound)
+	}
+
+	rv := reflect.ValueOf(v)
+	if err := checkContainer(rv, seg); err != nil {
+		return nil, err
+	}
+
+	if !seg.isIndex {
+		key := reflect.ValueOf(seg.key).Convert(rv.Type().Key())
+		if !rv.MapIndex(key).IsValid() {
+			return nil, ErrPathNotFound
+		}
+		rv.SetMapIndex(key, reflect.Value{})
+		return v, nil
+	}
+
+	if rv.Kind() == reflect.Array {
+		return nil, fmt.Errorf("%w: cannot modify array %s", ErrTypeMismatch, rv.Type())
+	}
+	if seg.index >= rv.Len() {
+		return nil, fmt.Errorf("%w: index out of range with length %d", ErrPathNotFound, rv.Len())
+	}
+
+	return reflect.AppendSlice(rv.Slice(0, seg.index), rv.Slice(seg.index+1, rv.Len())).Interface(), nil
+}
+
+// checkContainer returns an error if rv cannot hold seg.
+func checkContainer(rv reflect.Value, seg pathSegment) error {
+	if !rv.IsValid() {
+		return fmt.Errorf("%w: parent is null", ErrPathNotFound)
+	}
+
+	if seg.isIndex {
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Errorf("%w: cannot index %s with [%d]", ErrTypeMismatch, rv.Type(), seg.index)
+		}
+		return nil
+	}
+
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: cannot look up key %q in %s", ErrTypeMismatch, seg.key, rv.Type())
+	}
+
+	return nil
+}
+
+// assignable returns c as a value of type t.
+func assignable(c any, t reflect.Type) (reflect.Value, error) {
+	if c == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Map, reflect.Slice, reflect.Ptr:
+			return reflect.Zero(t), nil
+		}
+	} else if cv := reflect.ValueOf(c); cv.Type().AssignableTo(t) {
+		return cv, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%w: cannot assign %T to element of type %s", ErrTypeMismatch, c, t)
+}
+
+// setIn assigns value at segs[i:] within v and returns the updated v.
+// A nil v is replaced by a new container.
+func setIn(v any, segs []pathSegment, i int, value any) (any, error) {
+	seg := segs[i]
+
+	if v == nil {
+		if seg.isIndex {
+			v = []any{}
+		} else {
+			v = map[string]any{}
+		}
+	}
+
+	c := value
+	if i < len(segs)-1 {
+		existing, err := child(v, seg)
+		if err != nil && !errors.Is(err, ErrPathNotFound) {
+			return nil, pathError(segs, i, err)
+		}
+
+		if c, err = setIn(existing, segs, i+1, value); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := withChild(v, seg, c)
+	if err != nil {
+		return nil, pathError(segs, i, err)
+	}
+
+	return v, nil
+}
+
+// deleteIn removes the value at segs[i:] from v and returns the updated v.
+func deleteIn(v any, segs []pathSegment, i int) (any, error) {
+	seg := segs[i]
+
+	if i == len(segs)-1 {
+		v, err := withoutChild(v, seg)
+		if err != nil {
+			return nil, pathError(segs, i, err)
+		}
+		return v, nil
+	}
+
+	c, err := child(v, seg)
+	if err != nil {
+		return nil, pathError(segs, i, err)
+	}
+
+	if c, err = deleteIn(c, segs, i+1); err != nil {
+		return nil, err
+	}
+
+	if v, err = withChild(v, seg, c); err != nil {
+		return nil, pathError(segs, i, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,253 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPathDict() map[string]any {
+	return map[string]any{
+		"spec": map[string]any{
+			"items": []any{
+				map[string]any{"name": "a", "count": float64(2)},
+				map[string]any{"name": "b", "count": 3, "tags": []string{"x", "y"}},
+			},
+			"labels":  map[string]string{"app.kubernetes.io/name": "web"},
+			"enabled": true,
+			"ratio":   0.5,
+			"big":     json.Number("12"),
+			"nothing": nil,
+		},
+	}
+}
+
+func TestGet(t *testing.T) {
+	dict := newPathDict()
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{path: "spec.items[0].name", want: "a"},
+		{path: "spec.items[1].tags[1]", want: "y"},
+		{path: `spec.labels["app.kubernetes.io/name"]`, want: "web"},
+		{path: `["spec"].enabled`, want: true},
+		{path: "spec.nothing", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := convert.Get(dict, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, convert.Has(dict, tt.path))
+		})
+	}
+}
+
+func TestGet_Errors(t *testing.T) {
+	dict := newPathDict()
+
+	tests := []struct {
+		path     string
+		wantPath string
+		wantErr  error
+	}{
+		{path: "spec.missing.name", wantPath: "spec.missing", wantErr: convert.ErrPathNotFound},
+		{path: "spec.items[2].name", wantPath: "spec.items[2]", wantErr: convert.ErrPathNotFound},
+		{path: "spec.nothing.name", wantPath: "spec.nothing.name", wantErr: convert.ErrPathNotFound},
+		{path: "spec.items.name", wantPath: "spec.items", wantErr: convert.ErrTypeMismatch},
+		{path: "spec.enabled[0]", wantPath: "spec.enabled", wantErr: convert.ErrTypeMismatch},
+		{path: `spec.labels["app.kubernetes.io/name"].x`, wantPath: `spec.labels["app.kubernetes.io/name"]`, wantErr: convert.ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := convert.Get(dict, tt.path)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			var pathErr *convert.PathError
+			require.True(t, errors.As(err, &pathErr), "error should be a *PathError")
+			assert.Equal(t, tt.wantPath, pathErr.Path)
+			assert.False(t, convert.Has(dict, tt.path))
+		})
+	}
+}
+
+func TestGet_InvalidPath(t *testing.T) {
+	for _, path := range []string{"", ".a", "a.", "a..b", "a[", "a[x]", "a[-1]", `a["b`, `a["b"`, "a[0]b"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := convert.Get(map[string]any{}, path)
+			assert.ErrorIs(t, err, convert.ErrInvalidPath)
+		})
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	dict := newPathDict()
+
+	name, err := convert.GetString(dict, "spec.items[0].name")
+	require.NoError(t, err)
+	assert.Equal(t, "a", name)
+
+	count, err := convert.GetInt(dict, "spec.items[0].count")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "integral float64 is coerced")
+
+	big, err := convert.GetInt(dict, "spec.big")
+	require.NoError(t, err)
+	assert.Equal(t, 12, big, "json.Number is coerced")
+
+	ratio, err := convert.GetFloat(dict, "spec.items[1].count")
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), ratio)
+
+	enabled, err := convert.GetBool(dict, "spec.enabled")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	tags, err := convert.GetSlice(dict, "spec.items[1].tags")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"x", "y"}, tags, "typed slices are copied into []any")
+
+	labels, err := convert.GetMap(dict, "spec.labels")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"app.kubernetes.io/name": "web"}, labels)
+
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	it, err := convert.GetAs[item](dict, "spec.items[0]")
+	require.NoError(t, err)
+	assert.Equal(t, item{Name: "a", Count: 2}, it)
+}
+
+func TestTypedGetters_Errors(t *testing.T) {
+	dict := newPathDict()
+
+	tests := []struct {
+		name    string
+		get     func() error
+		wantErr error
+	}{
+		{
+			name:    "fractional",
+			get:     func() error { _, err := convert.GetInt(dict, "spec.ratio"); return err },
+			wantErr: convert.ErrNotInteger,
+		},
+		{
+			name:    "string for int",
+			get:     func() error { _, err := convert.GetInt(dict, "spec.items[0].name"); return err },
+			wantErr: convert.ErrTypeMismatch,
+		},
+		{
+			name:    "null",
+			get:     func() error { _, err := convert.GetString(dict, "spec.nothing"); return err },
+			wantErr: convert.ErrTypeMismatch,
+		},
+		{
+			name:    "map for slice",
+			get:     func() error { _, err := convert.GetSlice(dict, "spec"); return err },
+			wantErr: convert.ErrTypeMismatch,
+		},
+		{
+			name:    "missing",
+			get:     func() error { _, err := convert.GetBool(dict, "spec.missing"); return err },
+			wantErr: convert.ErrPathNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get()
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := convert.GetInt(dict, "spec.items[0].name")
+	var fieldErr *convert.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "spec.items[0].name", fieldErr.Path)
+}
+
+func TestSet(t *testing.T) {
+	dict := newPathDict()
+
+	require.NoError(t, convert.Set(dict, "spec.items[0].name", "renamed"))
+	require.NoError(t, convert.Set(dict, "spec.items[2].name", "appended"))
+	require.NoError(t, convert.Set(dict, "spec.items[1].tags[2]", "z"))
+	require.NoError(t, convert.Set(dict, `spec.labels["tier"]`, "frontend"))
+	require.NoError(t, convert.Set(dict, "meta.owners[0].name", "alice"))
+	require.NoError(t, convert.Set(dict, "spec.nothing.created", true))
+
+	for path, want := range map[string]any{
+		"spec.items[0].name":    "renamed",
+		"spec.items[2].name":    "appended",
+		"spec.items[1].tags[2]": "z",
+		"spec.labels.tier":      "frontend",
+		"meta.owners[0].name":   "alice",
+		"spec.nothing.created":  true,
+	} {
+		got, err := convert.Get(dict, path)
+		require.NoError(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+
+	assert.Equal(t, []string{"x", "y", "z"}, dict["spec"].(map[string]any)["items"].([]any)[1].(map[string]any)["tags"])
+}
+
+func TestSet_Errors(t *testing.T) {
+	dict := newPathDict()
+
+	err := convert.Set(dict, "spec.items[5].name", "x")
+	assert.ErrorIs(t, err, convert.ErrPathNotFound)
+
+	var pathErr *convert.PathError
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, "spec.items[5]", pathErr.Path)
+
+	err = convert.Set(dict, "spec.enabled.value", 1)
+	assert.ErrorIs(t, err, convert.ErrTypeMismatch)
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, "spec.enabled", pathErr.Path)
+
+	err = convert.Set(dict, "spec.items[1].tags[0]", 1)
+	assert.ErrorIs(t, err, convert.ErrTypeMismatch, "int cannot be stored in []string")
+
+	assert.ErrorIs(t, convert.Set(nil, "a", 1), convert.ErrTypeMismatch)
+}
+
+func TestDelete(t *testing.T) {
+	dict := newPathDict()
+
+	require.NoError(t, convert.Delete(dict, "spec.items[0]"))
+	require.NoError(t, convert.Delete(dict, "spec.items[0].tags[0]"))
+	require.NoError(t, convert.Delete(dict, `spec.labels["app.kubernetes.io/name"]`))
+	require.NoError(t, convert.Delete(dict, "spec.enabled"))
+
+	items, err := convert.GetSlice(dict, "spec.items")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "b", items[0].(map[string]any)["name"])
+	assert.Equal(t, []string{"y"}, items[0].(map[string]any)["tags"])
+
+	assert.False(t, convert.Has(dict, "spec.enabled"))
+	assert.Empty(t, dict["spec"].(map[string]any)["labels"])
+
+	err = convert.Delete(dict, "spec.enabled")
+	assert.ErrorIs(t, err, convert.ErrPathNotFound)
+
+	var pathErr *convert.PathError
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, "spec.enabled", pathErr.Path)
+}
@@ -0,0 +1,250 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var protoMessageType = reflect.TypeFor[proto.Message]()
+
+// wellKnownPackage is the package of the protobuf well-known types.
+const wellKnownPackage protoreflect.FullName = "google.protobuf"
+
+// wellKnownJSON lists the well-known types with a special JSON
+// representation, which are converted with protojson.
+var wellKnownJSON = map[protoreflect.Name]bool{
+	"Any":         true,
+	"Timestamp":   true,
+	"Duration":    true,
+	"Struct":      true,
+	"Value":       true,
+	"ListValue":   true,
+	"FieldMask":   true,
+	"Empty":       true,
+	"DoubleValue": true,
+	"FloatValue":  true,
+	"Int64Value":  true,
+	"UInt64Value": true,
+	"Int32Value":  true,
+	"UInt32Value": true,
+	"BoolValue":   true,
+	"StringValue": true,
+	"BytesValue":  true,
+}
+
+// isProtoMessage reports whether t or *t implements proto.Message.
+func isProtoMessage(t reflect.Type) bool {
+	return t.Implements(protoMessageType) ||
+		(t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(protoMessageType))
+}
+
+// newProtoEncoder returns the encoder for a type implementing proto.Message
+// through a value or pointer receiver.
+func newProtoEncoder(t reflect.Type) encoderFunc {
+	if t.Implements(protoMessageType) {
+		return protoMessageEncoder
+	}
+
+	// Generated messages implement proto.Message on the pointer. Values that
+	// are not addressable are copied so the message methods can be used.
+	return func(e *encodeState, v reflect.Value) (any, error) {
+		if !v.CanAddr() {
+			p := reflect.New(t)
+			p.Elem().Set(v)
+			v = p.Elem()
+		}
+		return encodeProtoMessage(e, v.Addr().Interface().(proto.Message).ProtoReflect())
+	}
+}
+
+func protoMessageEncoder(e *encodeState, v reflect.Value) (any, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+
+	return encodeProtoMessage(e, v.Interface().(proto.Message).ProtoReflect())
+}
+
+// encodeProtoMessage converts m into the shape protojson would produce for
+// it, preserving primitive Go types for scalar fields. Messages, lists and
+// maps count as one level each for WithMaxDepth.
+func encodeProtoMessage(e *encodeState, m protoreflect.Message) (any, error) {
+	if !m.IsValid() {
+		return nil, nil
+	}
+
+	desc := m.Descriptor()
+	if desc.FullName().Parent() == wellKnownPackage && wellKnownJSON[desc.Name()] {
+		data, err := protojson.Marshal(m.Interface())
+		out, err := decodeMarshaled(reflect.TypeOf(m.Interface()), data, err)
+		if err != nil {
+			return nil, err
+		}
+
+		// google.protobuf.Struct and ListValue nest arbitrarily
+		levels := decodedDepth(out)
+		if err := e.descend(levels); err != nil {
+			return nil, err
+		}
+		e.ascend(levels)
+
+		return out, nil
+	}
+
+	if err := e.descend(1); err != nil {
+		return nil, err
+	}
+	defer e.ascend(1)
+
+	d := make(map[string]any, desc.Fields().Len())
+
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := fd.JSONName()
+		if fd.IsExtension() {
+			name = "[" + string(fd.FullName()) + "]"
+		}
+
+		var value any
+		if value, err = encodeProtoField(e, fd, v); err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			return false
+		}
+		d[name] = value
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func encodeProtoField(e *encodeState, fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	if fd.IsList() || fd.IsMap() {
+		if err := e.descend(1); err != nil {
+			return nil, err
+		}
+		defer e.ascend(1)
+	}
+
+	switch {
+	case fd.IsList():
+		list := v.List()
+		out := make([]any, list.Len())
+		for i := range out {
+			elem, err := encodeProtoSingular(e, fd, list.Get(i))
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = elem
+		}
+		return out, nil
+	case fd.IsMap():
+		out := make(map[string]any, v.Map().Len())
+
+		var err error
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			var elem any
+			if elem, err = encodeProtoSingular(e, fd.MapValue(), v); err != nil {
+				err = fmt.Errorf("%s: %w", k.String(), err)
+				return false
+			}
+			out[k.String()] = elem
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	return encodeProtoSingular(e, fd, v)
+}
+
+func encodeProtoSingular(e *encodeState, fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return int32(v.Int()), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int(), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return uint32(v.Uint()), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint(), nil
+	case protoreflect.FloatKind:
+		if f, ok := nonFiniteFloat(v.Float()); ok {
+			return f, nil
+		}
+		return float32(v.Float()), nil
+	case protoreflect.DoubleKind:
+		if f, ok := nonFiniteFloat(v.Float()); ok {
+			return f, nil
+		}
+		return v.Float(), nil
+	case protoreflect.StringKind:
+		return v.String(), nil
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+	case protoreflect.EnumKind:
+		if fd.Enum().FullName() == wellKnownPackage+".NullValue" {
+			return nil, nil
+		}
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), nil
+		}
+		return int32(v.Enum()), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return encodeProtoMessage(e, v.Message())
+	}
+
+	return nil, fmt.Errorf("%w: protobuf kind %s", ErrUnsupportedType, fd.Kind())
+}
+
+// decodedDepth returns the number of nested dict and slice levels of a
+// decoded JSON value.
+func decodedDepth(v any) int {
+	depth := 0
+
+	switch x := v.(type) {
+	case map[string]any:
+		for _, elem := range x {
+			depth = max(depth, decodedDepth(elem))
+		}
+	case []any:
+		for _, elem := range x {
+			depth = max(depth, decodedDepth(elem))
+		}
+	default:
+		return 0
+	}
+
+	return depth + 1
+}
+
+// nonFiniteFloat returns the protojson string for NaN and infinite values,
+// which have no JSON number representation.
+func nonFiniteFloat(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "NaN", true
+	case math.IsInf(f, 1):
+		return "Infinity", true
+	case math.IsInf(f, -1):
+		return "-Infinity", true
+	}
+
+	return "", false
+}
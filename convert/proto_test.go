@@ -0,0 +1,157 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/kopexa-grc/x/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestJSONToDict_Proto(t *testing.T) {
+	cred := &vault.Credential{
+		SecretId: "sec-1",
+		Type:     vault.CredentialType_private_key,
+		User:     "admin",
+		Secret:   []byte("s3cr3t"),
+	}
+
+	result, err := convert.JSONToDict(cred)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"secretId": "sec-1",
+		"type":     "private_key",
+		"user":     "admin",
+		"secret":   "czNjcjN0",
+	}, result)
+
+	data, err := protojson.Marshal(cred)
+	require.NoError(t, err)
+	assertJSONEq(t, string(data), result)
+}
+
+func TestJSONToDict_ProtoNested(t *testing.T) {
+	field := &typepb.Field{
+		Kind:        typepb.Field_TYPE_INT64,
+		Cardinality: typepb.Field_CARDINALITY_REPEATED,
+		Number:      7,
+		Name:        "ids",
+		Packed:      true,
+		Options: []*typepb.Option{
+			{Name: "deprecated"},
+		},
+	}
+
+	type export struct {
+		Field    *typepb.Field             `json:"field"`
+		Fields   []*typepb.Field           `json:"fields"`
+		ByName   map[string]*typepb.Field  `json:"by_name"`
+		Missing  *typepb.Field             `json:"missing"`
+		At       *timestamppb.Timestamp    `json:"at"`
+		Took     *durationpb.Duration      `json:"took"`
+		Attrs    *structpb.Struct          `json:"attrs"`
+		Count    *wrapperspb.Int32Value    `json:"count"`
+		Embedded vault.SecretID            `json:"embedded"`
+		Keys     map[string]vault.SecretID `json:"keys"`
+	}
+
+	attrs, err := structpb.NewStruct(map[string]any{"env": "prod", "tags": []any{"a"}})
+	require.NoError(t, err)
+
+	in := export{
+		Field:    field,
+		Fields:   []*typepb.Field{field},
+		ByName:   map[string]*typepb.Field{"ids": field},
+		At:       timestamppb.New(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+		Took:     durationpb.New(1500 * time.Millisecond),
+		Attrs:    attrs,
+		Count:    wrapperspb.Int32(3),
+		Embedded: vault.SecretID{Key: "k"},
+		Keys:     map[string]vault.SecretID{"a": {Key: "ka"}},
+	}
+
+	want := map[string]any{
+		"kind":        "TYPE_INT64",
+		"cardinality": "CARDINALITY_REPEATED",
+		"number":      int32(7),
+		"name":        "ids",
+		"packed":      true,
+		"options":     []any{map[string]any{"name": "deprecated"}},
+	}
+
+	result, err := convert.JSONToDict(&in)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"field":    want,
+		"fields":   []any{want},
+		"by_name":  map[string]any{"ids": want},
+		"missing":  nil,
+		"at":       "2024-03-01T12:00:00Z",
+		"took":     "1.500s",
+		"attrs":    map[string]any{"env": "prod", "tags": []any{"a"}},
+		"count":    float64(3),
+		"embedded": map[string]any{"key": "k"},
+		"keys":     map[string]any{"a": map[string]any{"key": "ka"}},
+	}, result)
+}
+
+func TestJSONToDict_ProtoMaxDepth(t *testing.T) {
+	// the message, its options list and each option make three levels
+	field := &typepb.Field{Name: "ids", Options: []*typepb.Option{{Name: "deprecated"}}}
+
+	_, err := convert.JSONToDict(field, convert.WithMaxDepth(3))
+	require.NoError(t, err)
+
+	_, err = convert.JSONToDict(field, convert.WithMaxDepth(2))
+	require.ErrorIs(t, err, convert.ErrMaxDepthExceeded)
+	assert.Contains(t, err.Error(), "options")
+
+	// well-known types count the levels of their JSON representation
+	attrs, err := structpb.NewStruct(map[string]any{"tags": []any{"a"}})
+	require.NoError(t, err)
+
+	in := struct {
+		Attrs *structpb.Struct `json:"attrs"`
+	}{Attrs: attrs}
+
+	_, err = convert.JSONToDict(in, convert.WithMaxDepth(3))
+	require.NoError(t, err)
+
+	_, err = convert.JSONToDict(in, convert.WithMaxDepth(2))
+	assert.ErrorIs(t, err, convert.ErrMaxDepthExceeded)
+}
+
+func TestJSONToDictSlice_Proto(t *testing.T) {
+	creds := []*vault.Credential{
+		{SecretId: "a", Type: vault.CredentialType_password},
+		nil,
+	}
+
+	result, err := convert.JSONToDictSlice(creds)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"secretId": "a", "type": "password"},
+		nil,
+	}, result)
+}
+
+// assertJSONEq asserts that v marshals to the JSON document want.
+func assertJSONEq(t *testing.T, want string, v any) {
+	t.Helper()
+
+	got, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, want, string(got))
+}
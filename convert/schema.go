@@ -0,0 +1,414 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SchemaDraft is the JSON Schema dialect of schemas generated by SchemaFor.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Schema is a JSON Schema (draft 2020-12) document or subschema. It covers
+// the keywords generated by SchemaFor and a few common validation keywords,
+// and marshals to and from its JSON representation.
+type Schema struct {
+	Schema string             `json:"$schema,omitempty"`
+	Ref    string             `json:"$ref,omitempty"`
+	Defs   map[string]*Schema `json:"$defs,omitempty"`
+
+	Type  SchemaType `json:"type,omitempty"`
+	Enum  []any      `json:"enum,omitempty"`
+	AnyOf []*Schema  `json:"anyOf,omitempty"`
+	Not   *Schema    `json:"not,omitempty"`
+
+	// numbers
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// strings
+	Pattern         string `json:"pattern,omitempty"`
+	Format          string `json:"format,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// objects
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+
+	// arrays
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+}
+
+// SchemaType lists the JSON types a value may have. A single type is
+// marshaled as a string, several as an array.
+type SchemaType []string
+
+// MarshalJSON implements json.Marshaler.
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// String returns the types joined with "or".
+func (t SchemaType) String() string {
+	return strings.Join(t, " or ")
+}
+
+// SchemaOption configures SchemaFor.
+type SchemaOption func(*schemaOptions)
+
+type schemaOptions struct {
+	disallowUnknownFields bool
+}
+
+// WithDisallowUnknownFields rejects object properties that do not match a
+// struct field. By default they are allowed, as DictToStruct and
+// encoding/json ignore them.
+func WithDisallowUnknownFields() SchemaOption {
+	return func(o *schemaOptions) {
+		o.disallowUnknownFields = true
+	}
+}
+
+// SchemaFor returns the JSON Schema (draft 2020-12) of the dictionaries
+// JSONToDict produces for values of type T and DictToStruct accepts.
+//
+// # Mapping Rules
+//
+// Struct fields are named and promoted with the json tag rules of
+// JSONToDict. Fields are required unless tagged omitempty or omitzero.
+//
+//   - bool, integers, floats and strings become boolean, integer (with the
+//     bounds of sized integer types), number and string
+//   - Pointers, slices and maps also allow null, as nil values convert to
+//     nil
+//   - Named struct types are defined once in $defs and referenced with
+//     $ref, so recursive types are supported; anonymous structs are inlined
+//   - Maps become objects whose additionalProperties describe the values;
+//     integer keys are constrained with propertyNames
+//   - []byte becomes a base64 string and time.Time a date-time string
+//   - Types implementing encoding.TextMarshaler become strings; interfaces,
+//     protobuf messages and other types implementing json.Marshaler accept
+//     any value
+//   - Fields tagged json:",string" become strings
+//
+// Pointers at the top level are dereferenced.
+//
+// # Errors
+//
+// Returns ErrUnsupportedType if T contains a type without a JSON
+// representation, such as a channel or function.
+//
+// # Examples
+//
+//	type Config struct {
+//		Name     string            `json:"name"`
+//		Replicas uint8             `json:"replicas,omitempty"`
+//		Labels   map[string]string `json:"labels,omitempty"`
+//	}
+//
+//	schema, err := SchemaFor[Config]()
+//	// schema (as JSON): {
+//	//   "$schema": "https://json-schema.org/draft/2020-12/schema",
+//	//   "type": "object",
+//	//   "properties": {
+//	//     "name": {"type": "string"},
+//	//     "replicas": {"type": "integer", "minimum": 0, "maximum": 255},
+//	//     "labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}}
+//	//   },
+//	//   "required": ["name"]
+//	// }
+func SchemaFor[T any](opts ...SchemaOption) (*Schema, error) {
+	g := &schemaGenerator{
+		defs:  map[string]*Schema{},
+		names: map[reflect.Type]string{},
+	}
+	for _, opt := range opts {
+		opt(&g.opts)
+	}
+
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var (
+		s   *Schema
+		err error
+	)
+	if t.Kind() == reflect.Struct && !hasMarshaler(t) {
+		g.root = t
+		s, err = g.structSchema(t)
+	} else {
+		s, err = g.schema(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.Schema = SchemaDraft
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+
+	return s, nil
+}
+
+// schemaGenerator builds the schema of a type and its definitions.
+type schemaGenerator struct {
+	opts schemaOptions
+
+	// root is the struct type described by the document itself
+	root reflect.Type
+
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+// schema returns the schema of values of type t.
+func (g *schemaGenerator) schema(t reflect.Type) (*Schema, error) {
+	if t.Kind() == reflect.Ptr {
+		s, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}, nil
+	case isProtoMessage(t),
+		t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{}, nil
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: SchemaType{"string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return integerSchema(t), nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	case reflect.Map:
+		return g.mapSchema(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !hasMarshaler(t.Elem()) {
+			return &Schema{Type: SchemaType{"string", "null"}, ContentEncoding: "base64"}, nil
+		}
+		s, err := g.arraySchema(t)
+		if err != nil {
+			return nil, err
+		}
+		s.Type = append(s.Type, "null")
+		return s, nil
+	case reflect.Array:
+		s, err := g.arraySchema(t)
+		if err != nil {
+			return nil, err
+		}
+		s.MinItems, s.MaxItems = ptrTo(t.Len()), ptrTo(t.Len())
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+}
+
+// integerSchema returns the schema of integer type t with its bounds. The
+// bounds of 64-bit types are not representable as float64 and only the
+// minimum of unsigned types is set.
+func integerSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: SchemaType{"integer"}}
+
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		bits := t.Bits()
+		s.Minimum = ptrTo(-math.Ldexp(1, bits-1))
+		s.Maximum = ptrTo(math.Ldexp(1, bits-1) - 1)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		s.Minimum = ptrTo(0.0)
+		s.Maximum = ptrTo(math.Ldexp(1, t.Bits()) - 1)
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		s.Minimum = ptrTo(0.0)
+	}
+
+	return s
+}
+
+// ref returns a reference to the definition of the named struct type t,
+// adding the definition on first use.
+func (g *schemaGenerator) ref(t reflect.Type) (*Schema, error) {
+	if t == g.root {
+		return &Schema{Ref: "#"}, nil
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t)
+		g.names[t] = name
+
+		// reserve the name, recursive references only need the $ref
+		g.defs[name] = nil
+		s, err := g.structSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = s
+	}
+
+	return &Schema{Ref: "#/$defs/" + pointerEscaper.Replace(name)}, nil
+}
+
+// defName returns an unused definition name for t.
+func (g *schemaGenerator) defName(t reflect.Type) string {
+	base := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, t.Name())
+
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := g.defs[name]; !taken {
+			return name
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) (*Schema, error) {
+	fields := cachedTypeFields(t)
+	s := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema, len(fields))}
+
+	for _, f := range fields {
+		var (
+			fs  *Schema
+			err error
+		)
+		if f.quoted {
+			fs = &Schema{Type: SchemaType{"string"}}
+			if f.typ.Kind() == reflect.Ptr {
+				fs = nullable(fs)
+			}
+		} else if fs, err = g.schema(f.typ); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+
+		s.Properties[f.name] = fs
+		if !f.omitEmpty && !f.omitZero {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+
+	if g.opts.disallowUnknownFields {
+		s.AdditionalProperties = &Schema{Not: &Schema{}}
+	}
+
+	return s, nil
+}
+
+func (g *schemaGenerator) mapSchema(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: SchemaType{"object", "null"}}
+
+	kt := t.Key()
+	switch {
+	case kt.Kind() == reflect.String, kt.Implements(textMarshalerType):
+	case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Int64:
+		s.PropertyNames = &Schema{Pattern: "^-?[0-9]+$"}
+	case kt.Kind() >= reflect.Uint && kt.Kind() <= reflect.Uintptr:
+		s.PropertyNames = &Schema{Pattern: "^[0-9]+$"}
+	default:
+		return nil, fmt.Errorf("%w: map key %s", ErrUnsupportedType, kt)
+	}
+
+	elem, err := g.schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	if !elem.isEmpty() {
+		s.AdditionalProperties = elem
+	}
+
+	return s, nil
+}
+
+func (g *schemaGenerator) arraySchema(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: SchemaType{"array"}}
+
+	elem, err := g.schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	if !elem.isEmpty() {
+		s.Items = elem
+	}
+
+	return s, nil
+}
+
+// nullable returns s extended to allow null.
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.isEmpty():
+		return s
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: SchemaType{"null"}}}}
+	case len(s.AnyOf) > 0:
+		return s
+	case len(s.Type) > 0 && !slices.Contains(s.Type, "null"):
+		s.Type = append(s.Type, "null")
+	}
+
+	return s
+}
+
+// isEmpty reports whether s accepts any value.
+func (s *Schema) isEmpty() bool {
+	return reflect.ValueOf(*s).IsZero()
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
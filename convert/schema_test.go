@@ -0,0 +1,253 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/kopexa-grc/x/multierr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaMeta struct {
+	Owner   string    `json:"owner"`
+	Created time.Time `json:"created,omitzero"`
+}
+
+type schemaControl struct {
+	ID       string           `json:"id"`
+	Children []*schemaControl `json:"children,omitempty"`
+}
+
+type schemaConfig struct {
+	schemaMeta
+	Name     string            `json:"name"`
+	Replicas uint8             `json:"replicas,omitempty"`
+	Weight   float64           `json:"weight,omitempty"`
+	Enabled  *bool             `json:"enabled"`
+	Count    int64             `json:"count,string,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    map[int]int16     `json:"ports,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Key      []byte            `json:"key,omitempty"`
+	Addr     netip.Addr        `json:"addr,omitzero"`
+	Extra    any               `json:"extra,omitempty"`
+	Controls []schemaControl   `json:"controls,omitempty"`
+	Parent   *schemaConfig     `json:"parent,omitempty"`
+	Window   [2]int            `json:"window,omitzero"`
+	Inline   struct {
+		On bool `json:"on"`
+	} `json:"inline,omitzero"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := convert.SchemaFor[*schemaConfig]()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"owner": {"type": "string"},
+			"created": {"type": "string", "format": "date-time"},
+			"name": {"type": "string"},
+			"replicas": {"type": "integer", "minimum": 0, "maximum": 255},
+			"weight": {"type": "number"},
+			"enabled": {"type": ["boolean", "null"]},
+			"count": {"type": "string"},
+			"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+			"ports": {
+				"type": ["object", "null"],
+				"propertyNames": {"pattern": "^-?[0-9]+$"},
+				"additionalProperties": {"type": "integer", "minimum": -32768, "maximum": 32767}
+			},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"key": {"type": ["string", "null"], "contentEncoding": "base64"},
+			"addr": {"type": "string"},
+			"extra": {},
+			"controls": {"type": ["array", "null"], "items": {"$ref": "#/$defs/schemaControl"}},
+			"parent": {"anyOf": [{"$ref": "#"}, {"type": "null"}]},
+			"window": {"type": "array", "items": {"type": "integer"}, "minItems": 2, "maxItems": 2},
+			"inline": {"type": "object", "properties": {"on": {"type": "boolean"}}, "required": ["on"]}
+		},
+		"required": ["owner", "name", "enabled"],
+		"$defs": {
+			"schemaControl": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"children": {
+						"type": ["array", "null"],
+						"items": {"anyOf": [{"$ref": "#/$defs/schemaControl"}, {"type": "null"}]}
+					}
+				},
+				"required": ["id"]
+			}
+		}
+	}`, string(data))
+
+	var decoded convert.Schema
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, schema, &decoded)
+}
+
+func TestSchemaFor_Options(t *testing.T) {
+	schema, err := convert.SchemaFor[schemaMeta](convert.WithDisallowUnknownFields())
+	require.NoError(t, err)
+	assert.Equal(t, &convert.Schema{Not: &convert.Schema{}}, schema.AdditionalProperties)
+
+	schema, err = convert.SchemaFor[[]schemaMeta]()
+	require.NoError(t, err)
+	assert.Equal(t, convert.SchemaType{"array", "null"}, schema.Type)
+	assert.Equal(t, "#/$defs/schemaMeta", schema.Items.Ref)
+	assert.Contains(t, schema.Defs, "schemaMeta")
+}
+
+func TestSchemaFor_Errors(t *testing.T) {
+	_, err := convert.SchemaFor[struct {
+		Ch chan int `json:"ch"`
+	}]()
+	require.ErrorIs(t, err, convert.ErrUnsupportedType)
+	assert.Contains(t, err.Error(), "ch")
+
+	_, err = convert.SchemaFor[map[float64]string]()
+	assert.ErrorIs(t, err, convert.ErrUnsupportedType)
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := convert.SchemaFor[schemaConfig](convert.WithDisallowUnknownFields())
+	require.NoError(t, err)
+
+	valid := map[string]any{
+		"owner":    "grc",
+		"created":  "2024-03-01T12:00:00Z",
+		"name":     "api",
+		"replicas": float64(3),
+		"enabled":  nil,
+		"ports":    map[string]any{"80": 8080},
+		"controls": []any{map[string]any{"id": "AC-1", "children": []any{map[string]any{"id": "AC-1.1"}, nil}}},
+		"parent":   map[string]any{"owner": "grc", "name": "base", "enabled": true},
+		"window":   []any{1, 2},
+	}
+	assert.NoError(t, schema.Validate(valid))
+
+	// dicts produced by JSONToDict validate as well
+	enabled := true
+	dict, err := convert.JSONToDict(schemaConfig{
+		schemaMeta: schemaMeta{Owner: "grc", Created: time.Now()},
+		Name:       "api",
+		Enabled:    &enabled,
+		Count:      42,
+		Labels:     map[string]string{"env": "prod"},
+		Key:        []byte("secret"),
+		Addr:       netip.MustParseAddr("10.0.0.1"),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, schema.Validate(dict))
+
+	var invalid map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"owner": "grc",
+		"created": "yesterday",
+		"replicas": 300,
+		"weight": "heavy",
+		"enabled": false,
+		"ports": {"http": 80, "443": 1.5},
+		"controls": [{"children": [{"id": 7}]}],
+		"window": [1],
+		"colour": "blue"
+	}`), &invalid))
+
+	got := map[string]string{}
+	for _, err := range schemaErrors(t, schema.Validate(invalid)) {
+		require.ErrorIs(t, err, convert.ErrSchemaViolation)
+		_, reason, _ := strings.Cut(err.Err.Error(), ": ")
+		got[err.Pointer] = reason
+	}
+	assert.Equal(t, map[string]string{
+		"/colour":                "property is not allowed",
+		"/controls/0/children/0": "value does not match any of the allowed schemas",
+		"/controls/0/id":         "required property is missing",
+		"/created":               `"yesterday" is not a date-time`,
+		"/name":                  "required property is missing",
+		"/ports/443":             "expected integer, got number",
+		"/ports/http":            `"http" does not match pattern "^-?[0-9]+$"`,
+		"/replicas":              "300 is greater than the maximum 255",
+		"/weight":                "expected number, got string",
+		"/window":                "1 items are fewer than the minimum 2",
+	}, got)
+}
+
+func TestSchema_ValidateBigIntegers(t *testing.T) {
+	const big = int64(1<<53 + 1)
+
+	var schema convert.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{"properties": {
+		"id": {},
+		"max": {"type": "integer", "maximum": 9007199254740992},
+		"min": {"type": "integer", "minimum": 9007199254740992}
+	}}`), &schema))
+	schema.Properties["id"].Enum = []any{big, uint64(math.MaxUint64)}
+
+	assert.NoError(t, schema.Validate(map[string]any{"id": json.Number("9007199254740993"), "max": int64(1 << 53), "min": uint64(1 << 53)}))
+	assert.NoError(t, schema.Validate(map[string]any{"id": json.Number("18446744073709551615")}))
+
+	got := map[string]string{}
+	for _, err := range schemaErrors(t, schema.Validate(map[string]any{"id": big - 1, "max": big, "min": big - 2})) {
+		_, reason, _ := strings.Cut(err.Err.Error(), ": ")
+		got[err.Pointer] = reason
+	}
+	assert.Equal(t, map[string]string{
+		"/id":  "value is not one of [9007199254740993 18446744073709551615]",
+		"/max": "9007199254740993 is greater than the maximum 9.007199254740992e+15",
+		"/min": "9007199254740991 is less than the minimum 9.007199254740992e+15",
+	}, got)
+}
+
+func TestSchema_ValidateInvalidSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "unresolvable ref", schema: `{"properties": {"a": {"$ref": "#/$defs/missing"}}}`},
+		{name: "ref cycle", schema: `{"$defs": {"a": {"$ref": "#/$defs/a"}}, "properties": {"a": {"$ref": "#/$defs/a"}}}`},
+		{name: "invalid pattern", schema: `{"properties": {"a": {"pattern": "("}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema convert.Schema
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+
+			errs := schemaErrors(t, schema.Validate(map[string]any{"a": "x"}))
+			require.Len(t, errs, 1)
+			assert.ErrorIs(t, errs[0], convert.ErrInvalidSchema)
+			assert.Equal(t, "/a", errs[0].Pointer)
+		})
+	}
+}
+
+// schemaErrors returns the *SchemaError values aggregated in err.
+func schemaErrors(t *testing.T, err error) []*convert.SchemaError {
+	t.Helper()
+
+	var errs *multierr.Errors
+	require.ErrorAs(t, err, &errs)
+
+	schemaErrs := make([]*convert.SchemaError, len(errs.Errors))
+	for i, err := range errs.Errors {
+		require.ErrorAs(t, err, &schemaErrs[i])
+	}
+
+	return schemaErrs
+}
@@ -0,0 +1,243 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"reflect"
+)
+
+// ErrEncoderClosed indicates a DictEncoder was used after Close
+var ErrEncoderClosed = errors.New("dict encoder is closed")
+
+// JSONToDictSeq returns an iterator over the elements of a slice or array
+// converted like JSONToDictSlice does, without building the result slice.
+//
+// Elements are converted lazily as the iterator advances, so only one
+// converted element is held at a time. Elements removed by WithExclude,
+// WithInclude or CycleSkip are not yielded; paths start at the slice, as for
+// JSONToDictSlice.
+//
+// # Errors
+//
+// If v is not a slice, array or pointer to one, or an option is invalid,
+// the iterator yields a single error. If an element cannot be converted,
+// the iterator yields the error, wrapped with the element index, and stops.
+//
+// # Examples
+//
+//	for dict, err := range JSONToDictSeq(controls) {
+//		if err != nil {
+//			return err
+//		}
+//		export(dict)
+//	}
+func JSONToDictSeq(v any, opts ...DictOption) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		rv, err := sliceValue(v)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !rv.IsValid() {
+			return
+		}
+
+		e := newEncodeState(newDictOptions(opts))
+		defer e.release()
+
+		// the slice itself is the first level
+		if err := cmp.Or(e.err, e.descend(1)); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		enc := typeEncoder(rv.Type().Elem())
+		for i := range rv.Len() {
+			elem, err := encodeElem(e, enc, i, rv.Index(i))
+			if err == errSkipValue {
+				continue
+			}
+			if !yield(elem, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// StreamFormat selects how a DictEncoder writes elements.
+type StreamFormat int
+
+const (
+	// StreamNDJSON writes each element as a JSON document followed by a
+	// newline (newline-delimited JSON).
+	StreamNDJSON StreamFormat = iota
+
+	// StreamJSONArray writes the elements as a single JSON array.
+	StreamJSONArray
+)
+
+// DictEncoder converts values like JSONToDictSlice converts elements and
+// writes them to an io.Writer as they are encoded, so that memory stays
+// bounded by the size of a single element.
+//
+// Each element is written with one call to the underlying writer; wrap it in
+// a bufio.Writer to reduce the number of writes for small elements. A
+// DictEncoder is not safe for concurrent use.
+type DictEncoder struct {
+	w      io.Writer
+	format StreamFormat
+	opts   *dictOptions
+
+	// index is the position of the next element for paths and errors
+	index int
+
+	// written is the number of elements written
+	written int
+
+	closed bool
+	err    error
+}
+
+// NewDictEncoder returns an encoder writing to w in format. opts apply to
+// every element as for JSONToDictSlice, with paths starting at the stream
+// as if it were a slice.
+func NewDictEncoder(w io.Writer, format StreamFormat, opts ...DictOption) *DictEncoder {
+	o := newDictOptions(opts)
+
+	return &DictEncoder{w: w, format: format, opts: o, err: o.err}
+}
+
+// Encode converts v and writes it as the next element. Elements removed by
+// WithExclude, WithInclude or CycleSkip are not written.
+//
+// After an error, Encode and Close return the same error.
+func (enc *DictEncoder) Encode(v any) error {
+	if enc.err != nil {
+		return enc.err
+	}
+	if enc.closed {
+		return ErrEncoderClosed
+	}
+
+	e := newEncodeState(enc.opts)
+	defer e.release()
+
+	if err := e.descend(1); err != nil {
+		enc.err = err
+		return err
+	}
+
+	index := enc.index
+	enc.index++
+
+	// v is converted like an element of []any
+	elem, err := encodeElem(e, interfaceEncoder, index, reflect.ValueOf(&v).Elem())
+	switch {
+	case err == errSkipValue:
+		return nil
+	case err != nil:
+		enc.err = err
+		return err
+	}
+
+	return enc.write(elem)
+}
+
+// write marshals elem and writes it with its separators.
+func (enc *DictEncoder) write(elem any) error {
+	data, err := json.Marshal(elem)
+	if err != nil {
+		enc.err = err
+		return err
+	}
+
+	if enc.format == StreamJSONArray {
+		sep := byte(',')
+		if enc.written == 0 {
+			sep = '['
+		}
+		data = append([]byte{sep}, data...)
+	} else {
+		data = append(data, '\n')
+	}
+
+	if _, err := enc.w.Write(data); err != nil {
+		enc.err = err
+		return err
+	}
+	enc.written++
+
+	return nil
+}
+
+// Written returns the number of elements written so far.
+func (enc *DictEncoder) Written() int {
+	return enc.written
+}
+
+// Close completes the stream. For StreamJSONArray it writes the closing
+// bracket, or an empty array if no element was written. Close does not
+// close the underlying writer.
+func (enc *DictEncoder) Close() error {
+	if enc.err != nil {
+		return enc.err
+	}
+	if enc.closed {
+		return nil
+	}
+	enc.closed = true
+
+	if enc.format != StreamJSONArray {
+		return nil
+	}
+
+	end := "]\n"
+	if enc.written == 0 {
+		end = "[]\n"
+	}
+
+	if _, err := io.WriteString(enc.w, end); err != nil {
+		enc.err = err
+		return err
+	}
+
+	return nil
+}
+
+// EncodeDictSlice converts the elements of a slice or array like
+// JSONToDictSlice and writes them to w in format as they are converted,
+// without building the result slice.
+//
+// # Errors
+//
+// Returns the errors of JSONToDictSlice and of writing to w. Elements
+// written before an error remain written, so a StreamJSONArray may be
+// incomplete.
+//
+// # Examples
+//
+//	w := bufio.NewWriter(file)
+//	if err := EncodeDictSlice(w, controls, StreamNDJSON, WithExclude("[*].secret")); err != nil {
+//		return err
+//	}
+//	return w.Flush()
+func EncodeDictSlice(w io.Writer, v any, format StreamFormat, opts ...DictOption) error {
+	enc := NewDictEncoder(w, format)
+
+	for elem, err := range JSONToDictSeq(v, opts...) {
+		if err != nil {
+			return err
+		}
+		if err := enc.write(elem); err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
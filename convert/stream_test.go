@@ -0,0 +1,162 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamControl struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret,omitempty"`
+}
+
+func newStreamInput() []any {
+	return []any{
+		streamControl{ID: "AC-1", Secret: "s1"},
+		&streamControl{ID: "AC-2"},
+		"plain",
+		nil,
+		map[string]int{"n": 1},
+	}
+}
+
+func TestJSONToDictSeq(t *testing.T) {
+	want, err := convert.JSONToDictSlice(newStreamInput())
+	require.NoError(t, err)
+
+	var got []any
+	for elem, err := range convert.JSONToDictSeq(newStreamInput()) {
+		require.NoError(t, err)
+		got = append(got, elem)
+	}
+	assert.Equal(t, want, got)
+
+	for range convert.JSONToDictSeq(nil) {
+		t.Fatal("nil input must not yield")
+	}
+}
+
+func TestJSONToDictSeq_Lazy(t *testing.T) {
+	converted := 0
+	count := convert.WithTransformer(func(c streamControl) (any, error) {
+		converted++
+		return c.ID, nil
+	})
+
+	controls := []streamControl{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	for elem, err := range convert.JSONToDictSeq(controls, count) {
+		require.NoError(t, err)
+		assert.Equal(t, "a", elem)
+		break
+	}
+	assert.Equal(t, 1, converted)
+}
+
+func TestJSONToDictSeq_Errors(t *testing.T) {
+	var errs []error
+	for _, err := range convert.JSONToDictSeq("not a slice") {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], convert.ErrInvalidSliceInput)
+
+	in := []any{streamControl{ID: "a"}, struct{ Ch chan int }{}, streamControl{ID: "c"}}
+
+	var elems []any
+	errs = nil
+	for elem, err := range convert.JSONToDictSeq(in) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		elems = append(elems, elem)
+	}
+	assert.Len(t, elems, 1, "iteration stops at the first error")
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], convert.ErrUnsupportedType)
+	assert.Contains(t, errs[0].Error(), "[1]")
+}
+
+func TestEncodeDictSlice(t *testing.T) {
+	want, err := convert.JSONToDictSlice(newStreamInput(), convert.WithExclude("[*].secret"))
+	require.NoError(t, err)
+
+	t.Run("ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, convert.EncodeDictSlice(&buf, newStreamInput(), convert.StreamNDJSON,
+			convert.WithExclude("[*].secret")))
+
+		lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+		require.Len(t, lines, len(want))
+		for i, line := range lines {
+			assertJSONEq(t, string(line), want[i])
+		}
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, convert.EncodeDictSlice(&buf, newStreamInput(), convert.StreamJSONArray,
+			convert.WithExclude("[*].secret")))
+		assertJSONEq(t, buf.String(), want)
+	})
+
+	t.Run("empty json array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, convert.EncodeDictSlice(&buf, []streamControl{}, convert.StreamJSONArray))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		err := convert.EncodeDictSlice(&bytes.Buffer{}, 42, convert.StreamNDJSON)
+		assert.ErrorIs(t, err, convert.ErrInvalidSliceInput)
+	})
+}
+
+func TestDictEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := convert.NewDictEncoder(&buf, convert.StreamJSONArray, convert.WithExclude("[1]", "[*].secret"))
+
+	for _, c := range []streamControl{{ID: "a", Secret: "x"}, {ID: "b"}, {ID: "c"}} {
+		require.NoError(t, enc.Encode(c))
+	}
+	require.NoError(t, enc.Encode(nil))
+	assert.Equal(t, 3, enc.Written())
+
+	require.NoError(t, enc.Close())
+	require.NoError(t, enc.Close())
+	assert.ErrorIs(t, enc.Encode(streamControl{}), convert.ErrEncoderClosed)
+
+	var got []any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, []any{map[string]any{"id": "a"}, map[string]any{"id": "c"}, nil}, got)
+}
+
+var errWrite = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestDictEncoder_Errors(t *testing.T) {
+	enc := convert.NewDictEncoder(failingWriter{}, convert.StreamNDJSON)
+	require.ErrorIs(t, enc.Encode(streamControl{ID: "a"}), errWrite)
+	assert.ErrorIs(t, enc.Encode(streamControl{ID: "b"}), errWrite, "errors are sticky")
+	assert.ErrorIs(t, enc.Close(), errWrite)
+
+	enc = convert.NewDictEncoder(&bytes.Buffer{}, convert.StreamNDJSON, convert.WithInclude("[x]"))
+	assert.ErrorIs(t, enc.Encode(streamControl{}), convert.ErrInvalidPath)
+
+	enc = convert.NewDictEncoder(&bytes.Buffer{}, convert.StreamNDJSON)
+	err := enc.Encode(struct{ Ch chan int }{})
+	require.ErrorIs(t, err, convert.ErrUnsupportedType)
+	assert.Contains(t, err.Error(), "[0]")
+}
@@ -0,0 +1,574 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidStructTarget indicates the target type of DictToStruct is not a struct
+	ErrInvalidStructTarget = errors.New("cannot convert from dict: target must be struct or pointer to struct")
+
+	// ErrTypeMismatch indicates a value cannot be converted to the target field type
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrNumberOverflow indicates a number does not fit into the target field type
+	ErrNumberOverflow = errors.New("number overflows target type")
+
+	// ErrNotInteger indicates a number with a fractional part was assigned to an integer field
+	ErrNotInteger = errors.New("number is not an integer")
+)
+
+// FieldError describes a failure to convert the value at a specific path.
+//
+// Path uses dot notation for struct fields and map keys and brackets for
+// slice indexes, e.g. "spec.items[3].name". Use errors.Is with
+// ErrTypeMismatch, ErrNumberOverflow or ErrNotInteger to determine the reason.
+type FieldError struct {
+	// Path is the location of the value within the input dictionary.
+	Path string
+
+	// Type is the target type the value could not be converted to.
+	Type reflect.Type
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message including the field path.
+func (e *FieldError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "<root>"
+	}
+
+	return fmt.Sprintf("%s: cannot convert to %s: %v", path, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying reason for errors.Is and errors.As.
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// DictToStruct converts a dictionary into a value of type T.
+//
+// This function is the inverse of JSONToDict. It applies the same field naming
+// rules and converts values to the field types the way encoding/json would
+// after unmarshaling into map[string]any.
+//
+// # Conversion Rules
+//
+//   - Keys are matched against field names as resolved by JSONToDict,
+//     including promoted fields of embedded structs
+//   - Unknown keys are ignored, missing keys leave fields at their zero value
+//   - Fields tagged json:",string" accept their value encoded as a string
+//   - Numbers, including json.Number, are converted between numeric types;
+//     float64 values assigned to integer fields must be integral and within
+//     range
+//   - Nested maps are converted into nested structs and maps
+//   - Slices and arrays are converted element-wise into typed slices;
+//     byte slices also accept base64 strings
+//   - Types implementing json.Unmarshaler receive the JSON encoding of the
+//     value; types implementing encoding.TextUnmarshaler receive strings.
+//     time.Time fields therefore accept RFC 3339 strings
+//   - Map keys are converted from strings to integer and text unmarshaler keys
+//   - Pointer fields are allocated as needed; nil values produce nil pointers
+//   - Interface fields receive the value as is
+//
+// # Type Parameter
+//
+//   - T: a struct type or a pointer to a struct type
+//
+// # Errors
+//
+// Returns ErrInvalidStructTarget if T is not a struct or pointer to struct.
+// Conversion failures are returned as *FieldError carrying the path of the
+// offending value.
+//
+// # Examples
+//
+//	type Item struct {
+//		Name  string `json:"name"`
+//		Count int    `json:"count"`
+//	}
+//
+//	type Spec struct {
+//		Items   []Item    `json:"items"`
+//		Created time.Time `json:"created"`
+//	}
+//
+//	spec, err := DictToStruct[Spec](map[string]any{
+//		"items":   []any{map[string]any{"name": "a", "count": float64(2)}},
+//		"created": "2025-08-06T10:00:00Z",
+//	})
+//	// spec.Items[0].Count == 2
+//
+//	_, err = DictToStruct[Spec](map[string]any{
+//		"items": []any{map[string]any{"count": "two"}},
+//	})
+//	// err: items[0].count: cannot convert to int: type mismatch: got string
+func DictToStruct[T any](d map[string]any) (T, error) {
+	var result T
+
+	rv := reflect.ValueOf(&result).Elem()
+	target := rv
+	if rv.Kind() == reflect.Ptr {
+		if rv.Type().Elem().Kind() != reflect.Struct {
+			return result, fmt.Errorf("%w: got %s", ErrInvalidStructTarget, rv.Type())
+		}
+		rv.Set(reflect.New(rv.Type().Elem()))
+		target = rv.Elem()
+	}
+
+	if target.Kind() != reflect.Struct {
+		return result, fmt.Errorf("%w: got %s", ErrInvalidStructTarget, rv.Type())
+	}
+
+	if err := (decoder{}).decodeStruct("", d, target); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, nil
+}
+
+// decoder converts dictionary values into typed Go values.
+type decoder struct {
+	// coerce enables conversions between strings and numbers or booleans.
+	coerce bool
+}
+
+// decodeStruct assigns dictionary entries to the fields of dst.
+func (dec decoder) decodeStruct(path string, d map[string]any, dst reflect.Value) error {
+	for _, f := range cachedTypeFields(dst.Type()) {
+		src, ok := d[f.name]
+		if !ok {
+			continue
+		}
+
+		fv, ok := fieldByIndexAlloc(dst, f.index)
+		if !ok {
+			continue
+		}
+
+		fieldPath := joinPath(path, f.name)
+
+		if s, isString := src.(string); f.quoted && isString {
+			if err := json.Unmarshal([]byte(s), &src); err != nil {
+				return &FieldError{Path: fieldPath, Type: fv.Type(), Err: err}
+			}
+		}
+
+		if err := dec.decodeValue(fieldPath, src, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldByIndexAlloc returns the nested field of v at index, allocating nil
+// embedded pointers on the way. It reports false if an embedded pointer to
+// an unexported type cannot be allocated.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// decodeValue converts src and assigns it to dst.
+func (dec decoder) decodeValue(path string, src any, dst reflect.Value) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	dt := dst.Type()
+
+	// fast path: values that already have the right type
+	if sv.Type().AssignableTo(dt) {
+		dst.Set(sv)
+		return nil
+	}
+
+	if dst.Kind() != reflect.Ptr && dst.CanAddr() {
+		if ok, err := decodeUnmarshaler(path, src, dst); ok {
+			return err
+		}
+	}
+
+	if num, ok := src.(json.Number); ok {
+		switch dst.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			nv, err := numberValue(num)
+			if err != nil {
+				return &FieldError{Path: path, Type: dt, Err: err}
+			}
+			sv = nv
+		}
+	}
+
+	if dec.coerce {
+		cv, err := coerceScalar(sv, dt)
+		if err != nil {
+			return &FieldError{Path: path, Type: dt, Err: err}
+		}
+		sv = cv
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(dt.Elem())
+		if err := dec.decodeValue(path, src, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Struct:
+		return dec.decodeStructValue(path, sv, dst)
+	case reflect.Map:
+		return dec.decodeMap(path, sv, dst)
+	case reflect.Slice, reflect.Array:
+		return dec.decodeSlice(path, sv, dst)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decodeInt(path, sv, dst)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return decodeUint(path, sv, dst)
+	case reflect.Float32, reflect.Float64:
+		return decodeFloat(path, sv, dst)
+	case reflect.String, reflect.Bool:
+		if sv.Kind() == dst.Kind() {
+			dst.Set(sv.Convert(dt))
+			return nil
+		}
+	}
+
+	return mismatch(path, dt, src)
+}
+
+// decodeUnmarshaler converts src using the json.Unmarshaler or
+// encoding.TextUnmarshaler implementation of dst. It reports false if dst
+// implements neither or a text unmarshaler receives a non-string value.
+func decodeUnmarshaler(path string, src any, dst reflect.Value) (bool, error) {
+	switch u := dst.Addr().Interface().(type) {
+	case json.Unmarshaler:
+		data, err := json.Marshal(src)
+		if err == nil {
+			err = u.UnmarshalJSON(data)
+		}
+		if err != nil {
+			return true, &FieldError{Path: path, Type: dst.Type(), Err: err}
+		}
+		return true, nil
+	case encoding.TextUnmarshaler:
+		s, ok := src.(string)
+		if !ok {
+			return false, nil
+		}
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return true, &FieldError{Path: path, Type: dst.Type(), Err: err}
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (dec decoder) decodeStructValue(path string, sv reflect.Value, dst reflect.Value) error {
+	d, ok := sv.Interface().(map[string]any)
+	if !ok {
+		return mismatch(path, dst.Type(), sv.Interface())
+	}
+
+	return dec.decodeStruct(path, d, dst)
+}
+
+func (dec decoder) decodeMap(path string, sv reflect.Value, dst reflect.Value) error {
+	dt := dst.Type()
+
+	if sv.Kind() != reflect.Map || sv.Type().Key().Kind() != reflect.String {
+		return mismatch(path, dt, sv.Interface())
+	}
+
+	m := reflect.MakeMapWithSize(dt, sv.Len())
+	iter := sv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		keyPath := joinPath(path, key)
+
+		k, err := decodeMapKey(keyPath, key, dt.Key())
+		if err != nil {
+			return err
+		}
+
+		elem := reflect.New(dt.Elem()).Elem()
+		if err := dec.decodeValue(keyPath, iter.Value().Interface(), elem); err != nil {
+			return err
+		}
+		m.SetMapIndex(k, elem)
+	}
+
+	dst.Set(m)
+
+	return nil
+}
+
+// decodeMapKey converts a dictionary key into a map key of type kt, mirroring
+// encodeMapKey.
+func decodeMapKey(path, key string, kt reflect.Type) (reflect.Value, error) {
+	if kt.Kind() == reflect.String {
+		return reflect.ValueOf(key).Convert(kt), nil
+	}
+
+	k := reflect.New(kt)
+	if u, ok := k.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, &FieldError{Path: path, Type: kt, Err: err}
+		}
+		return k.Elem(), nil
+	}
+
+	switch kt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, kt.Bits())
+		if err != nil {
+			return reflect.Value{}, &FieldError{Path: path, Type: kt, Err: err}
+		}
+		k.Elem().SetInt(n)
+		return k.Elem(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, kt.Bits())
+		if err != nil {
+			return reflect.Value{}, &FieldError{Path: path, Type: kt, Err: err}
+		}
+		k.Elem().SetUint(n)
+		return k.Elem(), nil
+	}
+
+	return reflect.Value{}, &FieldError{Path: path, Type: kt, Err: fmt.Errorf("%w: unsupported map key", ErrTypeMismatch)}
+}
+
+func (dec decoder) decodeSlice(path string, sv reflect.Value, dst reflect.Value) error {
+	dt := dst.Type()
+
+	if sv.Kind() == reflect.String && isByteSlice(dt) {
+		b, err := base64.StdEncoding.DecodeString(sv.String())
+		if err != nil {
+			return &FieldError{Path: path, Type: dt, Err: err}
+		}
+		dst.SetBytes(b)
+		return nil
+	}
+
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return mismatch(path, dt, sv.Interface())
+	}
+
+	n := sv.Len()
+	out := dst
+	if dst.Kind() == reflect.Slice {
+		out = reflect.MakeSlice(dt, n, n)
+	} else if n > dst.Len() {
+		return &FieldError{Path: path, Type: dt, Err: fmt.Errorf("%w: %d elements do not fit", ErrTypeMismatch, n)}
+	}
+
+	for i := 0; i < n; i++ {
+		if err := dec.decodeValue(path+"["+strconv.Itoa(i)+"]", sv.Index(i).Interface(), out.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	dst.Set(out)
+
+	return nil
+}
+
+func decodeInt(path string, sv reflect.Value, dst reflect.Value) error {
+	var n int64
+
+	switch sv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = sv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := sv.Uint()
+		if u > math.MaxInt64 {
+			return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+		}
+		n = int64(u)
+	case reflect.Float32, reflect.Float64:
+		f := sv.Float()
+		if f != math.Trunc(f) {
+			return &FieldError{Path: path, Type: dst.Type(), Err: ErrNotInteger}
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+		}
+		n = int64(f)
+	default:
+		return mismatch(path, dst.Type(), sv.Interface())
+	}
+
+	if dst.OverflowInt(n) {
+		return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+	}
+
+	dst.SetInt(n)
+
+	return nil
+}
+
+func decodeUint(path string, sv reflect.Value, dst reflect.Value) error {
+	var n uint64
+
+	switch sv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := sv.Int()
+		if i < 0 {
+			return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+		}
+		n = uint64(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = sv.Uint()
+	case reflect.Float32, reflect.Float64:
+		f := sv.Float()
+		if f != math.Trunc(f) {
+			return &FieldError{Path: path, Type: dst.Type(), Err: ErrNotInteger}
+		}
+		if f < 0 || f >= math.MaxUint64 {
+			return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+		}
+		n = uint64(f)
+	default:
+		return mismatch(path, dst.Type(), sv.Interface())
+	}
+
+	if dst.OverflowUint(n) {
+		return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+	}
+
+	dst.SetUint(n)
+
+	return nil
+}
+
+func decodeFloat(path string, sv reflect.Value, dst reflect.Value) error {
+	var f float64
+
+	switch sv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(sv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f = float64(sv.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = sv.Float()
+	default:
+		return mismatch(path, dst.Type(), sv.Interface())
+	}
+
+	if dst.OverflowFloat(f) {
+		return &FieldError{Path: path, Type: dst.Type(), Err: ErrNumberOverflow}
+	}
+
+	dst.SetFloat(f)
+
+	return nil
+}
+
+// numberValue converts a json.Number into an int64 or float64 value.
+func numberValue(n json.Number) (reflect.Value, error) {
+	if i, err := n.Int64(); err == nil {
+		return reflect.ValueOf(i), nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf(f), nil
+}
+
+// coerceScalar converts strings into numbers and booleans and numbers and
+// booleans into strings as required by the scalar type dt. Other values are
+// returned unchanged.
+func coerceScalar(sv reflect.Value, dt reflect.Type) (reflect.Value, error) {
+	switch dt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if sv.Kind() != reflect.String {
+			return sv, nil
+		}
+
+		s := strings.TrimSpace(sv.String())
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return reflect.ValueOf(n), nil
+		}
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return reflect.ValueOf(n), nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return reflect.ValueOf(f), nil
+		}
+		return sv, fmt.Errorf("%w: %q is not a number", ErrTypeMismatch, sv.String())
+	case reflect.Bool:
+		if sv.Kind() != reflect.String {
+			return sv, nil
+		}
+
+		b, err := strconv.ParseBool(strings.TrimSpace(sv.String()))
+		if err != nil {
+			return sv, fmt.Errorf("%w: %q is not a boolean", ErrTypeMismatch, sv.String())
+		}
+		return reflect.ValueOf(b), nil
+	case reflect.String:
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return reflect.ValueOf(strconv.FormatInt(sv.Int(), 10)), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return reflect.ValueOf(strconv.FormatUint(sv.Uint(), 10)), nil
+		case reflect.Float32, reflect.Float64:
+			return reflect.ValueOf(strconv.FormatFloat(sv.Float(), 'f', -1, sv.Type().Bits())), nil
+		case reflect.Bool:
+			return reflect.ValueOf(strconv.FormatBool(sv.Bool())), nil
+		}
+	}
+
+	return sv, nil
+}
+
+// mismatch returns a FieldError for a value of an incompatible type.
+func mismatch(path string, target reflect.Type, src any) error {
+	return &FieldError{Path: path, Type: target, Err: fmt.Errorf("%w: got %T", ErrTypeMismatch, src)}
+}
+
+// joinPath appends a field name or map key to a dot-separated path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,241 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type structItem struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+type structSpec struct {
+	Title   string            `json:"title"`
+	Items   []structItem      `json:"items"`
+	Labels  map[string]string `json:"labels"`
+	Created time.Time         `json:"created"`
+	Owner   *structItem       `json:"owner"`
+	Ratio   float32           `json:"ratio"`
+	Size    uint8             `json:"size"`
+	Data    []byte            `json:"data"`
+	Extra   any               `json:"extra"`
+	Public  bool
+	hidden  string
+}
+
+func TestDictToStruct(t *testing.T) {
+	input := map[string]any{
+		"title": "spec",
+		"items": []any{
+			map[string]any{"name": "a", "count": float64(2), "tags": []any{"x", "y"}},
+			map[string]any{"name": "b", "count": 3},
+		},
+		"labels":  map[string]any{"env": "prod"},
+		"created": "2025-08-06T10:00:00Z",
+		"owner":   map[string]any{"name": "alice"},
+		"ratio":   0.5,
+		"size":    float64(200),
+		"extra":   map[string]any{"free": "form"},
+		"Public":  true,
+		"hidden":  "ignored",
+		"unknown": "ignored",
+	}
+
+	got, err := convert.DictToStruct[structSpec](input)
+	require.NoError(t, err)
+
+	assert.Equal(t, structSpec{
+		Title: "spec",
+		Items: []structItem{
+			{Name: "a", Count: 2, Tags: []string{"x", "y"}},
+			{Name: "b", Count: 3},
+		},
+		Labels:  map[string]string{"env": "prod"},
+		Created: time.Date(2025, 8, 6, 10, 0, 0, 0, time.UTC),
+		Owner:   &structItem{Name: "alice"},
+		Ratio:   0.5,
+		Size:    200,
+		Extra:   map[string]any{"free": "form"},
+		Public:  true,
+	}, got)
+}
+
+func TestDictToStruct_RoundTrip(t *testing.T) {
+	original := structSpec{
+		Title:   "round trip",
+		Items:   []structItem{{Name: "a", Count: 1, Tags: []string{"t"}}},
+		Labels:  map[string]string{"k": "v"},
+		Created: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Owner:   &structItem{Name: "bob"},
+		Size:    7,
+		Data:    []byte("data"),
+	}
+
+	// through JSON, where all numbers become float64
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	var dict map[string]any
+	require.NoError(t, json.Unmarshal(data, &dict))
+
+	got, err := convert.DictToStruct[structSpec](dict)
+	require.NoError(t, err)
+	assert.Equal(t, original, got)
+
+	// through JSONToDict, which preserves Go types
+	dict, err = convert.JSONToDict(original)
+	require.NoError(t, err)
+
+	got, err = convert.DictToStruct[structSpec](dict)
+	require.NoError(t, err)
+	assert.Equal(t, original, got)
+}
+
+type structBase struct {
+	ID string `json:"id"`
+}
+
+// StructAudit is exported so the embedded pointer can be allocated.
+type StructAudit struct {
+	By string `json:"by"`
+}
+
+type structTagged struct {
+	structBase
+	*StructAudit
+	Count   int64          `json:"count,string"`
+	Ratio   *float64       `json:"ratio,string"`
+	Label   string         `json:"label,string"`
+	ByID    map[int]string `json:"by_id"`
+	Skipped string         `json:"-"`
+}
+
+func TestDictToStruct_FieldRules(t *testing.T) {
+	ratio := 0.5
+	original := structTagged{
+		structBase:  structBase{ID: "x"},
+		StructAudit: &StructAudit{By: "promoted"},
+		Count:       42,
+		Ratio:       &ratio,
+		Label:       "quoted",
+		ByID:        map[int]string{1: "one"},
+	}
+
+	dict, err := convert.JSONToDict(original)
+	require.NoError(t, err)
+	assert.Equal(t, "42", dict["count"])
+
+	dict["Skipped"] = "ignored"
+
+	got, err := convert.DictToStruct[structTagged](dict)
+	require.NoError(t, err)
+	assert.Equal(t, original, got)
+
+	_, err = convert.DictToStruct[structTagged](map[string]any{"count": "many"})
+	var fieldErr *convert.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "count", fieldErr.Path)
+}
+
+func TestDictToStruct_Pointer(t *testing.T) {
+	got, err := convert.DictToStruct[*structItem](map[string]any{"name": "ptr"})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "ptr", got.Name)
+
+	got, err = convert.DictToStruct[*structItem](nil)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
+
+func TestDictToStruct_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    map[string]any
+		wantPath string
+		wantErr  error
+	}{
+		{
+			name:     "type mismatch in nested slice",
+			input:    map[string]any{"items": []any{map[string]any{}, map[string]any{"name": 42}}},
+			wantPath: "items[1].name",
+			wantErr:  convert.ErrTypeMismatch,
+		},
+		{
+			name:     "fractional number for int",
+			input:    map[string]any{"items": []any{map[string]any{"count": 1.5}}},
+			wantPath: "items[0].count",
+			wantErr:  convert.ErrNotInteger,
+		},
+		{
+			name:     "overflow",
+			input:    map[string]any{"size": float64(256)},
+			wantPath: "size",
+			wantErr:  convert.ErrNumberOverflow,
+		},
+		{
+			name:     "negative for unsigned",
+			input:    map[string]any{"size": -1},
+			wantPath: "size",
+			wantErr:  convert.ErrNumberOverflow,
+		},
+		{
+			name:     "map value mismatch",
+			input:    map[string]any{"labels": map[string]any{"env": 1}},
+			wantPath: "labels.env",
+			wantErr:  convert.ErrTypeMismatch,
+		},
+		{
+			name:     "scalar for struct",
+			input:    map[string]any{"owner": "alice"},
+			wantPath: "owner",
+			wantErr:  convert.ErrTypeMismatch,
+		},
+		{
+			name:     "scalar for slice",
+			input:    map[string]any{"items": "none"},
+			wantPath: "items",
+			wantErr:  convert.ErrTypeMismatch,
+		},
+		{
+			name:     "invalid time",
+			input:    map[string]any{"created": "yesterday"},
+			wantPath: "created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := convert.DictToStruct[structSpec](tt.input)
+			require.Error(t, err)
+
+			var fieldErr *convert.FieldError
+			require.True(t, errors.As(err, &fieldErr), "error should be a *FieldError")
+			assert.Equal(t, tt.wantPath, fieldErr.Path)
+			assert.Contains(t, err.Error(), tt.wantPath)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDictToStruct_InvalidTarget(t *testing.T) {
+	_, err := convert.DictToStruct[int](map[string]any{})
+	assert.ErrorIs(t, err, convert.ErrInvalidStructTarget)
+
+	_, err = convert.DictToStruct[*string](map[string]any{})
+	assert.ErrorIs(t, err, convert.ErrInvalidStructTarget)
+}
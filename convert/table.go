@@ -0,0 +1,415 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultJoinSeparator separates the values of slices joined into one cell.
+const DefaultJoinSeparator = "; "
+
+// TableFormat selects the output of WriteTable.
+type TableFormat int
+
+const (
+	// TableCSV writes comma-separated values as defined in RFC 4180.
+	TableCSV TableFormat = iota
+
+	// TableTSV writes tab-separated values, quoted like TableCSV.
+	TableTSV
+
+	// TableMarkdown writes a GitHub Flavored Markdown table.
+	TableMarkdown
+)
+
+// TableOption configures ToTable and WriteTable.
+type TableOption func(*tableOptions)
+
+type tableOptions struct {
+	sep            string
+	join           string
+	columns        []string
+	headers        map[string]string
+	explode        []string
+	escapeFormulas bool
+}
+
+// WithColumns selects the columns of the table and their order by their
+// flattened keys. Keys missing from a row produce empty cells. By default
+// all keys of all rows are used, sorted.
+func WithColumns(keys ...string) TableOption {
+	return func(o *tableOptions) {
+		o.columns = keys
+	}
+}
+
+// WithHeaders renames columns, mapping flattened keys to header names.
+// Columns without an entry use their key as header.
+func WithHeaders(headers map[string]string) TableOption {
+	return func(o *tableOptions) {
+		o.headers = headers
+	}
+}
+
+// WithKeySeparator sets the separator of flattened keys. The default is
+// DefaultSeparator.
+func WithKeySeparator(sep string) TableOption {
+	return func(o *tableOptions) {
+		o.sep = sep
+	}
+}
+
+// WithJoinSeparator sets the separator of slice values joined into one
+// cell. The default is DefaultJoinSeparator.
+func WithJoinSeparator(sep string) TableOption {
+	return func(o *tableOptions) {
+		o.join = sep
+	}
+}
+
+// WithExplode writes one row per element of the slice at each key instead
+// of joining the elements. The other cells of the row are repeated, and
+// rows with several exploded slices are repeated for every combination of
+// their elements. Rows where the slice is empty or missing are kept once.
+func WithExplode(keys ...string) TableOption {
+	return func(o *tableOptions) {
+		o.explode = append(slices.Clip(o.explode), keys...)
+	}
+}
+
+// WithFormulaEscaping controls whether WriteTable escapes CSV and TSV cells
+// that spreadsheet applications would evaluate as formulas. Such cells start
+// with =, +, -, @, a tab or a carriage return and are prefixed with a single
+// quote, unless they are numbers such as "-1.5". Escaping is enabled by
+// default; disable it for output that is not opened in spreadsheets.
+func WithFormulaEscaping(enabled bool) TableOption {
+	return func(o *tableOptions) {
+		o.escapeFormulas = enabled
+	}
+}
+
+func newTableOptions(opts []TableOption) *tableOptions {
+	o := &tableOptions{sep: DefaultSeparator, join: DefaultJoinSeparator, escapeFormulas: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// ToTable converts rows into a header and records of cells, for writing
+// with WriteTable or any other tabular format.
+//
+// Each row must be a dict, such as the elements returned by
+// JSONToDictSlice. Nested dicts are flattened into columns keyed by their
+// joined keys as with Flatten. Slices do not produce indexed columns:
+// their values are joined into one cell per key, e.g. the names of all
+// items become the "items.name" cell, unless they are exploded with
+// WithExplode.
+//
+// Cells hold strings as is, nil as an empty string, numbers and booleans
+// formatted like JSON, []byte as base64 and other values in their JSON
+// encoding.
+//
+// # Errors
+//
+// Returns ErrInvalidDictInput if a row is not a dict.
+//
+// # Examples
+//
+//	rows := []any{
+//		map[string]any{"id": "AC-1", "owner": map[string]any{"name": "alice"}, "tags": []any{"iam", "sso"}},
+//		map[string]any{"id": "AC-2", "tags": []string{}},
+//	}
+//
+//	header, records, err := ToTable(rows, WithColumns("id", "owner.name", "tags"))
+//	// header:  []string{"id", "owner.name", "tags"}
+//	// records: [][]string{{"AC-1", "alice", "iam; sso"}, {"AC-2", "", ""}}
+func ToTable(rows []any, opts ...TableOption) ([]string, [][]string, error) {
+	return newTableOptions(opts).table(rows)
+}
+
+func (o *tableOptions) table(rows []any) ([]string, [][]string, error) {
+	var flat []map[string][]string
+	for i, row := range rows {
+		d, ok := asDict(row)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: row %d is %T", ErrInvalidDictInput, i, row)
+		}
+
+		for _, d := range o.explodeRow(d, 0) {
+			flat = append(flat, o.cells(d))
+		}
+	}
+
+	columns := o.columns
+	if columns == nil {
+		// keys map to whether any row has a value for them
+		keys := map[string]bool{}
+		for _, cells := range flat {
+			for k, c := range cells {
+				keys[k] = keys[k] || len(c) > 0
+			}
+		}
+
+		sorted := slices.Sorted(maps.Keys(keys))
+		for _, k := range sorted {
+			// nil or empty values of nested dicts, such as a nil pointer
+			// to a struct, are covered by the columns of their keys
+			if !keys[k] && slices.ContainsFunc(sorted, func(c string) bool {
+				return strings.HasPrefix(c, k+o.sep)
+			}) {
+				continue
+			}
+			columns = append(columns, k)
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, k := range columns {
+		header[i] = k
+		if name, ok := o.headers[k]; ok {
+			header[i] = name
+		}
+	}
+
+	records := make([][]string, len(flat))
+	for i, cells := range flat {
+		record := make([]string, len(columns))
+		for j, k := range columns {
+			record[j] = strings.Join(cells[k], o.join)
+		}
+		records[i] = record
+	}
+
+	return header, records, nil
+}
+
+// explodeRow returns the rows produced by exploding d at o.explode[i:].
+func (o *tableOptions) explodeRow(d map[string]any, i int) []map[string]any {
+	if i == len(o.explode) {
+		return []map[string]any{d}
+	}
+
+	path := strings.Split(o.explode[i], o.sep)
+
+	s, ok := asSlice(lookupKeys(d, path))
+	if !ok || len(s) == 0 {
+		return o.explodeRow(d, i+1)
+	}
+
+	var rows []map[string]any
+	for _, elem := range s {
+		rows = append(rows, o.explodeRow(replaceKeys(d, path, elem), i+1)...)
+	}
+
+	return rows
+}
+
+// lookupKeys returns the value at the nested keys path within d.
+func lookupKeys(d map[string]any, path []string) any {
+	var v any = d
+	for _, k := range path {
+		d, ok := asDict(v)
+		if !ok {
+			return nil
+		}
+		v = d[k]
+	}
+
+	return v
+}
+
+// replaceKeys returns a copy of d with the value at the nested keys path
+// replaced by v. Only the dicts along path are copied.
+func replaceKeys(d map[string]any, path []string, v any) map[string]any {
+	out := maps.Clone(d)
+	if len(path) == 1 {
+		out[path[0]] = v
+		return out
+	}
+
+	child, _ := asDict(d[path[0]])
+	out[path[0]] = replaceKeys(child, path[1:], v)
+
+	return out
+}
+
+// cells returns the cells of d keyed by column. Keys are joined like
+// Flatten does, except that slice elements share the column of the slice.
+func (o *tableOptions) cells(d map[string]any) map[string][]string {
+	fo := &flattenOptions{sep: o.sep, indexes: indexJoined}
+	cells := map[string][]string{}
+
+	for k, v := range d {
+		fo.flattenValue(k, 1, v, func(key string, v any) {
+			if emptyCell(v) {
+				// empty values still produce their column
+				if _, ok := cells[key]; !ok {
+					cells[key] = nil
+				}
+				return
+			}
+			cells[key] = append(cells[key], formatCell(v))
+		})
+	}
+
+	return cells
+}
+
+// emptyCell reports whether v adds no text to its cell, which holds for nil
+// and empty slices.
+func emptyCell(v any) bool {
+	if v == nil {
+		return true
+	}
+	if _, ok := v.([]byte); ok {
+		return false
+	}
+
+	s, ok := asSlice(v)
+
+	return ok && len(s) == 0
+}
+
+// formatCell returns the text of a leaf value.
+func formatCell(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x)
+	case json.Number:
+		return x.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits())
+	}
+
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// WriteTable writes rows to w as a table in format. Rows are converted with
+// ToTable and opts. CSV and TSV cells are escaped against formula injection
+// unless WithFormulaEscaping(false) is given. Markdown cells escape
+// backslashes and pipes, and line breaks become <br>.
+//
+// # Errors
+//
+// Returns the errors of ToTable, an error for an unknown format and errors
+// from writing to w.
+//
+// # Examples
+//
+//	dicts, err := JSONToDictSlice(controls)
+//	if err != nil {
+//		return err
+//	}
+//
+//	err = WriteTable(os.Stdout, dicts, TableMarkdown,
+//		WithColumns("id", "owner.name"),
+//		WithHeaders(map[string]string{"id": "Control", "owner.name": "Owner"}),
+//	)
+//	// | Control | Owner |
+//	// | --- | --- |
+//	// | AC-1 | alice |
+func WriteTable(w io.Writer, rows []any, format TableFormat, opts ...TableOption) error {
+	o := newTableOptions(opts)
+
+	header, records, err := o.table(rows)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case TableCSV, TableTSV:
+		cw := csv.NewWriter(w)
+		if format == TableTSV {
+			cw.Comma = '\t'
+		}
+
+		if o.escapeFormulas {
+			escapeFormulas(header)
+			for _, record := range records {
+				escapeFormulas(record)
+			}
+		}
+
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+
+		return nil
+	case TableMarkdown:
+		return writeMarkdownTable(w, header, records)
+	}
+
+	return fmt.Errorf("unknown table format %d", format)
+}
+
+// escapeFormulas prefixes the cells that spreadsheet applications would
+// evaluate as formulas with a single quote.
+func escapeFormulas(cells []string) {
+	for i, cell := range cells {
+		if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			continue
+		}
+		cells[i] = "'" + cell
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func writeMarkdownTable(w io.Writer, header []string, records [][]string) error {
+	var b strings.Builder
+
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + markdownEscaper.Replace(cell) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(header)
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, record := range records {
+		writeRow(record)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
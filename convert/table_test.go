@@ -0,0 +1,174 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kopexa-grc/x/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tableOwner struct {
+	Name string `json:"name"`
+}
+
+type tableControl struct {
+	ID       string       `json:"id"`
+	Score    float64      `json:"score"`
+	Active   bool         `json:"active"`
+	Owner    *tableOwner  `json:"owner"`
+	Tags     []string     `json:"tags"`
+	Evidence []tableOwner `json:"evidence,omitempty"`
+}
+
+func newTableRows(t *testing.T) []any {
+	t.Helper()
+
+	rows, err := convert.JSONToDictSlice([]tableControl{
+		{
+			ID:       "AC-1",
+			Score:    1000000,
+			Active:   true,
+			Owner:    &tableOwner{Name: "alice"},
+			Tags:     []string{"iam", "sso"},
+			Evidence: []tableOwner{{Name: "policy.pdf"}, {Name: "audit.log"}},
+		},
+		{ID: "AC-2", Score: 0.5, Tags: []string{}},
+	})
+	require.NoError(t, err)
+
+	return rows
+}
+
+func TestToTable(t *testing.T) {
+	header, records, err := convert.ToTable(newTableRows(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"active", "evidence.name", "id", "owner.name", "score", "tags"}, header)
+	assert.Equal(t, [][]string{
+		{"true", "policy.pdf; audit.log", "AC-1", "alice", "1000000", "iam; sso"},
+		{"false", "", "AC-2", "", "0.5", ""},
+	}, records)
+}
+
+func TestToTable_Options(t *testing.T) {
+	header, records, err := convert.ToTable(newTableRows(t),
+		convert.WithColumns("id", "owner/name", "tags", "missing"),
+		convert.WithHeaders(map[string]string{"id": "Control", "owner/name": "Owner"}),
+		convert.WithKeySeparator("/"),
+		convert.WithJoinSeparator(", "),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Control", "Owner", "tags", "missing"}, header)
+	assert.Equal(t, [][]string{
+		{"AC-1", "alice", "iam, sso", ""},
+		{"AC-2", "", "", ""},
+	}, records)
+}
+
+func TestToTable_Explode(t *testing.T) {
+	_, records, err := convert.ToTable(newTableRows(t),
+		convert.WithColumns("id", "tags", "evidence.name"),
+		convert.WithExplode("tags", "evidence"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"AC-1", "iam", "policy.pdf"},
+		{"AC-1", "iam", "audit.log"},
+		{"AC-1", "sso", "policy.pdf"},
+		{"AC-1", "sso", "audit.log"},
+		{"AC-2", "", ""},
+	}, records)
+
+	// nested slices are exploded by their flattened key
+	rows := []any{map[string]any{"spec": map[string]any{"ports": []any{80, 443}, "name": "web"}}}
+	_, records, err = convert.ToTable(rows, convert.WithExplode("spec.ports"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"web", "80"}, {"web", "443"}}, records)
+	assert.Equal(t, []any{80, 443}, rows[0].(map[string]any)["spec"].(map[string]any)["ports"], "rows are not modified")
+}
+
+func TestToTable_Errors(t *testing.T) {
+	_, _, err := convert.ToTable([]any{map[string]any{}, "plain"})
+	require.ErrorIs(t, err, convert.ErrInvalidDictInput)
+	assert.Contains(t, err.Error(), "row 1")
+}
+
+func TestWriteTable(t *testing.T) {
+	rows := []any{
+		map[string]any{"id": "AC-1", "note": "a, \"quoted\"\nnote", "tags": []any{"x|y"}},
+		map[string]any{"id": "AC-2", "note": "tab\there"},
+		map[string]any{"id": `AC-3\`, "note": `a\|b`},
+	}
+
+	tests := []struct {
+		name   string
+		format convert.TableFormat
+		want   string
+	}{
+		{
+			name:   "csv",
+			format: convert.TableCSV,
+			want:   "id,note,tags\nAC-1,\"a, \"\"quoted\"\"\nnote\",x|y\nAC-2,tab\there,\nAC-3\\,a\\|b,\n",
+		},
+		{
+			name:   "tsv",
+			format: convert.TableTSV,
+			want:   "id\tnote\ttags\nAC-1\t\"a, \"\"quoted\"\"\nnote\"\tx|y\nAC-2\t\"tab\there\"\t\nAC-3\\\ta\\|b\t\n",
+		},
+		{
+			name:   "markdown",
+			format: convert.TableMarkdown,
+			want: "| id | note | tags |\n" +
+				"| --- | --- | --- |\n" +
+				"| AC-1 | a, \"quoted\"<br>note | x\\|y |\n" +
+				"| AC-2 | tab\there |  |\n" +
+				"| AC-3\\\\ | a\\\\\\|b |  |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, convert.WriteTable(&buf, rows, tt.format))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestWriteTable_FormulaEscaping(t *testing.T) {
+	rows := []any{
+		map[string]any{"a": "=HYPERLINK(\"x\")", "b": "+1+2", "c": "-A1", "d": "@SUM(A1)", "e": -1.5, "f": "\tx", "g": "a=b"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, convert.WriteTable(&buf, rows, convert.TableCSV))
+	assert.Equal(t, "a,b,c,d,e,f,g\n\"'=HYPERLINK(\"\"x\"\")\",'+1+2,'-A1,'@SUM(A1),-1.5,'\tx,a=b\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, convert.WriteTable(&buf, []any{map[string]any{"=cmd": "-A1"}}, convert.TableTSV))
+	assert.Equal(t, "'=cmd\n'-A1\n", buf.String(), "headers are escaped as well")
+
+	buf.Reset()
+	require.NoError(t, convert.WriteTable(&buf, rows, convert.TableCSV, convert.WithFormulaEscaping(false)))
+	assert.Equal(t, "a,b,c,d,e,f,g\n\"=HYPERLINK(\"\"x\"\")\",+1+2,-A1,@SUM(A1),-1.5,\"\tx\",a=b\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, convert.WriteTable(&buf, rows, convert.TableMarkdown, convert.WithColumns("c")))
+	assert.Equal(t, "| c |\n| --- |\n| -A1 |\n", buf.String(), "markdown is not escaped")
+}
+
+func TestWriteTable_Errors(t *testing.T) {
+	rows := []any{map[string]any{"id": "AC-1"}}
+
+	assert.ErrorIs(t, convert.WriteTable(failingWriter{}, rows, convert.TableCSV), errWrite)
+	assert.ErrorIs(t, convert.WriteTable(failingWriter{}, rows, convert.TableMarkdown), errWrite)
+	assert.ErrorIs(t, convert.WriteTable(&bytes.Buffer{}, []any{1}, convert.TableCSV), convert.ErrInvalidDictInput)
+	assert.Error(t, convert.WriteTable(&bytes.Buffer{}, rows, convert.TableFormat(42)))
+}
@@ -0,0 +1,374 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kopexa-grc/x/multierr"
+)
+
+var (
+	// ErrSchemaViolation indicates a value does not match its schema
+	ErrSchemaViolation = errors.New("schema violation")
+
+	// ErrInvalidSchema indicates a schema cannot be applied, such as an
+	// unresolvable $ref or an invalid pattern
+	ErrInvalidSchema = errors.New("invalid schema")
+)
+
+// maxRefChain limits the number of $ref resolved without descending into a
+// value, which only a schema referring to itself can exceed.
+const maxRefChain = 32
+
+// SchemaError describes a value that does not match its schema.
+type SchemaError struct {
+	// Pointer is the RFC 6901 JSON Pointer of the value, e.g.
+	// "/spec/items/3/name". The root value has the empty pointer.
+	Pointer string
+
+	// Err is the underlying reason, wrapping ErrSchemaViolation or
+	// ErrInvalidSchema.
+	Err error
+}
+
+// Error returns the error message including the pointer.
+func (e *SchemaError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "<root>"
+	}
+
+	return fmt.Sprintf("%s: %v", pointer, e.Err)
+}
+
+// Unwrap returns the underlying reason for errors.Is and errors.As.
+func (e *SchemaError) Unwrap() error { return e.Err }
+
+// Validate checks d against s and reports every mismatch.
+//
+// Values are classified the way encoding/json would marshal them, so
+// dictionaries decoded from JSON and dictionaries produced by JSONToDict can
+// both be validated: Go integers are integers, floats with an integral
+// value are integers as well, []byte is a string and maps and slices of any
+// type are objects and arrays.
+//
+// The keywords $ref (to "#" and "#/$defs/..."), type, enum, anyOf, not,
+// minimum, maximum, pattern, format "date-time", contentEncoding "base64",
+// properties, required, additionalProperties, propertyNames, items,
+// minItems and maxItems are validated; other keywords are ignored.
+//
+// # Errors
+//
+// Returns nil if d matches s. Otherwise the error is a *multierr.Errors with
+// one *SchemaError per mismatch. Properties are checked in key order, then
+// missing required properties are reported.
+//
+// # Examples
+//
+//	schema, _ := SchemaFor[Config]()
+//
+//	var d map[string]any
+//	_ = json.Unmarshal([]byte(`{"replicas": 300, "labels": {"env": 1}}`), &d)
+//
+//	err := schema.Validate(d)
+//	// err: 3 errors occurred:
+//	//   * /labels/env: schema violation: expected string, got integer
+//	//   * /replicas: schema violation: 300 is greater than the maximum 255
+//	//   * /name: schema violation: required property is missing
+func (s *Schema) Validate(d map[string]any) error {
+	v := validator{root: s, patterns: map[string]compiledPattern{}}
+	v.validate(s, d, nil, 0)
+
+	return v.errs.ErrorOrNil()
+}
+
+// validator collects the mismatches of a value against a schema document.
+type validator struct {
+	root *Schema
+	errs multierr.Errors
+
+	// patterns caches the compiled patterns of the document; it is shared
+	// with the validators of matches.
+	patterns map[string]compiledPattern
+}
+
+type compiledPattern struct {
+	re  *regexp.Regexp
+	err error
+}
+
+func (v *validator) violation(segs []pathSegment, format string, args ...any) {
+	v.errs.Add(&SchemaError{
+		Pointer: formatPointer(segs),
+		Err:     fmt.Errorf("%w: %s", ErrSchemaViolation, fmt.Sprintf(format, args...)),
+	})
+}
+
+func (v *validator) invalid(segs []pathSegment, format string, args ...any) {
+	v.errs.Add(&SchemaError{
+		Pointer: formatPointer(segs),
+		Err:     fmt.Errorf("%w: %s", ErrInvalidSchema, fmt.Sprintf(format, args...)),
+	})
+}
+
+// matches reports whether value matches s without recording mismatches.
+func (v *validator) matches(s *Schema, value any, segs []pathSegment, refs int) bool {
+	sub := validator{root: v.root, patterns: v.patterns}
+	sub.validate(s, value, segs, refs)
+
+	return sub.errs.IsEmpty()
+}
+
+// validate checks value at segs against s. refs counts the $ref resolved
+// since the last descent into a value.
+func (v *validator) validate(s *Schema, value any, segs []pathSegment, refs int) {
+	if s == nil {
+		return
+	}
+
+	if s.Ref != "" {
+		switch target, err := v.resolve(s.Ref); {
+		case err != nil:
+			v.invalid(segs, "%v", err)
+		case refs >= maxRefChain:
+			v.invalid(segs, "$ref %q refers to itself", s.Ref)
+		default:
+			v.validate(target, value, segs, refs+1)
+		}
+	}
+
+	typ := jsonTypeOf(value)
+	if len(s.Type) > 0 && !s.Type.allows(typ, value) {
+		v.violation(segs, "expected %s, got %s", s.Type, typ)
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return Equal(e, value) }) {
+		v.violation(segs, "value is not one of %v", s.Enum)
+	}
+
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *Schema) bool {
+		return v.matches(sub, value, segs, refs)
+	}) {
+		v.violation(segs, "value does not match any of the allowed schemas")
+	}
+
+	if s.Not != nil && v.matches(s.Not, value, segs, refs) {
+		v.violation(segs, "value is not allowed")
+	}
+
+	switch typ {
+	case "integer", "number":
+		v.validateNumber(s, value, segs)
+	case "string":
+		v.validateString(s, value, segs)
+	case "object":
+		d, _ := asDict(value)
+		v.validateObject(s, d, segs)
+	case "array":
+		a, _ := asSlice(value)
+		v.validateArray(s, a, segs)
+	}
+}
+
+// resolve returns the schema ref refers to within the root document.
+func (v *validator) resolve(ref string) (*Schema, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+
+	if name, ok := strings.CutPrefix(ref, "#/$defs/"); ok {
+		if s := v.root.Defs[pointerUnescaper.Replace(name)]; s != nil {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot resolve $ref %q", ref)
+}
+
+func (v *validator) validateNumber(s *Schema, value any, segs []pathSegment) {
+	n, _ := numberOf(value)
+
+	if s.Minimum != nil && n.compare(floatNumber(*s.Minimum)) < 0 {
+		v.violation(segs, "%v is less than the minimum %v", value, *s.Minimum)
+	}
+	if s.Maximum != nil && n.compare(floatNumber(*s.Maximum)) > 0 {
+		v.violation(segs, "%v is greater than the maximum %v", value, *s.Maximum)
+	}
+}
+
+func (v *validator) validateString(s *Schema, value any, segs []pathSegment) {
+	str, ok := value.(string)
+	if !ok {
+		// named string types; the content of []byte is not checked
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.String {
+			return
+		}
+		str = rv.String()
+	}
+
+	if s.Pattern != "" {
+		re, err := v.pattern(s.Pattern)
+		switch {
+		case err != nil:
+			v.invalid(segs, "pattern %q: %v", s.Pattern, err)
+		case !re.MatchString(str):
+			v.violation(segs, "%q does not match pattern %q", str, s.Pattern)
+		}
+	}
+
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			v.violation(segs, "%q is not a date-time", str)
+		}
+	}
+
+	if s.ContentEncoding == "base64" {
+		if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+			v.violation(segs, "value is not base64 encoded")
+		}
+	}
+}
+
+// pattern returns pattern compiled, compiling each pattern once.
+func (v *validator) pattern(pattern string) (*regexp.Regexp, error) {
+	p, ok := v.patterns[pattern]
+	if !ok {
+		p.re, p.err = regexp.Compile(pattern)
+		v.patterns[pattern] = p
+	}
+
+	return p.re, p.err
+}
+
+func (v *validator) validateObject(s *Schema, d map[string]any, segs []pathSegment) {
+	for _, k := range slices.Sorted(maps.Keys(d)) {
+		child := append(slices.Clip(segs), pathSegment{key: k})
+
+		if s.PropertyNames != nil {
+			v.validate(s.PropertyNames, k, child, 0)
+		}
+
+		if ps, ok := s.Properties[k]; ok {
+			v.validate(ps, d[k], child, 0)
+			continue
+		}
+
+		if s.AdditionalProperties.isFalse() {
+			v.violation(child, "property is not allowed")
+			continue
+		}
+		v.validate(s.AdditionalProperties, d[k], child, 0)
+	}
+
+	for _, k := range s.Required {
+		if _, ok := d[k]; !ok {
+			v.violation(append(slices.Clip(segs), pathSegment{key: k}), "required property is missing")
+		}
+	}
+}
+
+func (v *validator) validateArray(s *Schema, a []any, segs []pathSegment) {
+	if s.MinItems != nil && len(a) < *s.MinItems {
+		v.violation(segs, "%d items are fewer than the minimum %d", len(a), *s.MinItems)
+	}
+	if s.MaxItems != nil && len(a) > *s.MaxItems {
+		v.violation(segs, "%d items are more than the maximum %d", len(a), *s.MaxItems)
+	}
+
+	if s.Items == nil {
+		return
+	}
+
+	for i, elem := range a {
+		v.validate(s.Items, elem, append(slices.Clip(segs), pathSegment{index: i, isIndex: true}), 0)
+	}
+}
+
+// isFalse reports whether s is {"not": {}}, which no value matches.
+func (s *Schema) isFalse() bool {
+	if s == nil || s.Not == nil || !s.Not.isEmpty() {
+		return false
+	}
+
+	rest := *s
+	rest.Not = nil
+
+	return rest.isEmpty()
+}
+
+// allows reports whether a value of JSON type typ matches t. Integers match
+// number, and numbers with an integral value match integer.
+func (t SchemaType) allows(typ string, value any) bool {
+	if slices.Contains(t, typ) {
+		return true
+	}
+
+	switch typ {
+	case "integer":
+		return slices.Contains(t, "number")
+	case "number":
+		n, _ := numberOf(value)
+		return slices.Contains(t, "integer") && (!n.isFloat || n.f == math.Trunc(n.f) && !math.IsInf(n.f, 0))
+	}
+
+	return false
+}
+
+// jsonTypeOf returns the JSON type value is marshaled as, or its Go type if
+// it has no JSON representation.
+func jsonTypeOf(value any) string {
+	switch x := value.(type) {
+	case nil:
+		return "null"
+	case []byte:
+		return "string"
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Map:
+		if rv.IsNil() {
+			return "null"
+		}
+		if rv.Type().Key().Kind() == reflect.String {
+			return "object"
+		}
+	case reflect.Slice:
+		if rv.IsNil() {
+			return "null"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	}
+
+	return rv.Type().String()
+}
@@ -0,0 +1,241 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultChunkSize is the default maximum value length of a single chunk.
+	// It leaves room for the name and attributes within the ~4KB browser limit.
+	DefaultChunkSize = 3800
+
+	// DefaultMaxChunks is the default maximum number of chunks per cookie.
+	DefaultMaxChunks = 10
+)
+
+var (
+	// ErrTooManyChunks indicates that a value needs more chunks than allowed.
+	ErrTooManyChunks = errors.New("cookie: value exceeds maximum number of chunks")
+
+	// ErrMissingChunk indicates that a chunk of a chunked value is not present.
+	ErrMissingChunk = errors.New("cookie: missing chunk")
+)
+
+// ChunkOption configures chunked cookies.
+type ChunkOption func(*chunkOptions)
+
+type chunkOptions struct {
+	size      int
+	maxChunks int
+	codec     Codec
+}
+
+// WithChunkSize sets the maximum value length per chunk. Defaults to DefaultChunkSize.
+func WithChunkSize(size int) ChunkOption {
+	return func(o *chunkOptions) {
+		if size > 0 {
+			o.size = size
+		}
+	}
+}
+
+// WithMaxChunks sets the maximum number of chunks written or read.
+// Defaults to DefaultMaxChunks.
+func WithMaxChunks(n int) ChunkOption {
+	return func(o *chunkOptions) {
+		if n > 0 {
+			o.maxChunks = n
+		}
+	}
+}
+
+// WithChunkCodec encodes the whole value with codec before splitting it, and
+// decodes it after reassembly. The codec is bound to the base cookie name.
+func WithChunkCodec(codec Codec) ChunkOption {
+	return func(o *chunkOptions) {
+		o.codec = codec
+	}
+}
+
+func newChunkOptions(opts []ChunkOption) chunkOptions {
+	o := chunkOptions{
+		size:      DefaultChunkSize,
+		maxChunks: DefaultMaxChunks,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// ChunkName returns the name of the i-th chunk of the named cookie ("name.i").
+func ChunkName(name string, i int) string {
+	return name + "." + strconv.Itoa(i)
+}
+
+// NewChunked splits value across numbered cookies "name.0", "name.1", ...
+// that all share the attributes from config. The value of "name.0" starts
+// with the number of chunks followed by a colon ("3:..."), so that
+// ReadChunked can detect missing chunks.
+//
+// If r is not nil, chunks present in the request beyond the new chunk count
+// are expired, so that a shrinking value does not leave stale chunks behind.
+// If name is empty, config.Name is used.
+//
+// Example:
+//
+//	cookies, err := cookie.NewChunked(r, "id_token", token, config,
+//		cookie.WithChunkCodec(codec),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	for _, c := range cookies {
+//		http.SetCookie(w, c)
+//	}
+func NewChunked(r *http.Request, name, value string, config *Config, opts ...ChunkOption) ([]*http.Cookie, error) {
+	o := newChunkOptions(opts)
+
+	if name == "" {
+		name = config.Name
+	}
+
+	if name == "" {
+		return nil, ErrMissingName
+	}
+
+	if o.codec != nil {
+		encoded, err := o.codec.Encode(name, value)
+		if err != nil {
+			return nil, err
+		}
+		value = encoded
+	}
+
+	n := chunkCount(len(value), o.size, o.maxChunks)
+	if n > o.maxChunks {
+		return nil, fmt.Errorf("%w: more than %d chunks needed", ErrTooManyChunks, o.maxChunks)
+	}
+
+	value = strconv.Itoa(n) + ":" + value
+
+	cookies := make([]*http.Cookie, 0, n)
+	for i := 0; i < n; i++ {
+		end := min((i+1)*o.size, len(value))
+		cookies = append(cookies, New(ChunkName(name, i), value[i*o.size:end], config))
+	}
+
+	if r != nil {
+		for _, stale := range chunkIndexes(r, name) {
+			if stale >= n {
+				cookies = append(cookies, Expire(ChunkName(name, stale), config))
+			}
+		}
+	}
+
+	return cookies, nil
+}
+
+// ReadChunked reassembles a value written by NewChunked from the request.
+//
+// Returns http.ErrNoCookie if "name.0" is not present, ErrMalformedValue if
+// it does not start with the chunk count, ErrTooManyChunks if the count
+// exceeds the maximum number of chunks and ErrMissingChunk if any of the
+// counted chunks is not present.
+func ReadChunked(r *http.Request, name string, opts ...ChunkOption) (string, error) {
+	o := newChunkOptions(opts)
+
+	first, err := r.Cookie(ChunkName(name, 0))
+	if err != nil {
+		return "", err
+	}
+
+	count, value, ok := strings.Cut(first.Value, ":")
+	n, err := strconv.Atoi(count)
+	if !ok || err != nil || n < 1 {
+		return "", ErrMalformedValue
+	}
+
+	if n > o.maxChunks {
+		return "", fmt.Errorf("%w: %d chunks, %d allowed", ErrTooManyChunks, n, o.maxChunks)
+	}
+
+	var b strings.Builder
+	b.WriteString(value)
+	for i := 1; i < n; i++ {
+		c, err := r.Cookie(ChunkName(name, i))
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrMissingChunk, ChunkName(name, i))
+		}
+		b.WriteString(c.Value)
+	}
+
+	if o.codec != nil {
+		return o.codec.Decode(name, b.String())
+	}
+
+	return b.String(), nil
+}
+
+// chunkCount returns the number of chunks of the given size needed for a
+// value of the given length including the chunk count prefix. The prefix
+// must fit into the first chunk. Counts above maxChunks are not computed
+// exactly.
+func chunkCount(length, size, maxChunks int) int {
+	n := 1
+	for n <= maxChunks {
+		prefix := len(strconv.Itoa(n)) + 1
+		if prefix > size {
+			return maxChunks + 1
+		}
+
+		need := (length + prefix + size - 1) / size
+		if need <= n {
+			break
+		}
+		n = need
+	}
+
+	return n
+}
+
+// chunkBase returns the base cookie name of a chunk name ("name.i").
+func chunkBase(name string) (string, bool) {
+	i := strings.LastIndexByte(name, '.')
+	if i <= 0 {
+		return "", false
+	}
+
+	if n, err := strconv.Atoi(name[i+1:]); err != nil || n < 0 {
+		return "", false
+	}
+
+	return name[:i], true
+}
+
+// chunkIndexes returns the indexes of all chunks of the named cookie present in r.
+func chunkIndexes(r *http.Request, name string) []int {
+	prefix := name + "."
+
+	var indexes []int
+	for _, c := range r.Cookies() {
+		suffix, ok := strings.CutPrefix(c.Name, prefix)
+		if !ok {
+			continue
+		}
+
+		if i, err := strconv.Atoi(suffix); err == nil && i >= 0 {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
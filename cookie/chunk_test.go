@@ -0,0 +1,199 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func requestWith(cookies ...*http.Cookie) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range cookies {
+		r.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return r
+}
+
+func TestNewChunked(t *testing.T) {
+	config := &Config{Name: "token", Path: "/", Secure: true, HTTPOnly: true}
+
+	tests := []struct {
+		name       string
+		value      string
+		size       int
+		wantValues []string
+	}{
+		{name: "empty value", value: "", size: 4, wantValues: []string{"1:"}},
+		{name: "single chunk", value: "ab", size: 4, wantValues: []string{"1:ab"}},
+		{name: "multiple chunks", value: "abcdefghij", size: 4, wantValues: []string{"3:ab", "cdef", "ghij"}},
+		{name: "count prefix grows", value: strings.Repeat("x", 26), size: 3, wantValues: []string{
+			"10:", "xxx", "xxx", "xxx", "xxx", "xxx", "xxx", "xxx", "xxx", "xx",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies, err := NewChunked(nil, "", tt.value, config, WithChunkSize(tt.size))
+			if err != nil {
+				t.Fatalf("NewChunked() error = %v", err)
+			}
+
+			if len(cookies) != len(tt.wantValues) {
+				t.Fatalf("NewChunked() returned %d cookies, want %d", len(cookies), len(tt.wantValues))
+			}
+
+			for i, c := range cookies {
+				if want := ChunkName("token", i); c.Name != want {
+					t.Errorf("cookie[%d].Name = %q, want %q", i, c.Name, want)
+				}
+				if c.Value != tt.wantValues[i] {
+					t.Errorf("cookie[%d].Value = %q, want %q", i, c.Value, tt.wantValues[i])
+				}
+				if c.Path != "/" || !c.Secure || !c.HttpOnly {
+					t.Errorf("cookie[%d] = %+v, config not applied", i, c)
+				}
+			}
+
+			got, err := ReadChunked(requestWith(cookies...), "token")
+			if err != nil {
+				t.Fatalf("ReadChunked() error = %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("ReadChunked() = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestNewChunked_ClearsStaleChunks(t *testing.T) {
+	config := &Config{Name: "token", Path: "/app", Domain: "example.com"}
+
+	r := requestWith(
+		&http.Cookie{Name: "token.0", Value: "aaaa"},
+		&http.Cookie{Name: "token.1", Value: "bbbb"},
+		&http.Cookie{Name: "token.2", Value: "cc"},
+		&http.Cookie{Name: "other.3", Value: "x"},
+	)
+
+	cookies, err := NewChunked(r, "", "tiny", config, WithChunkSize(4))
+	if err != nil {
+		t.Fatalf("NewChunked() error = %v", err)
+	}
+
+	if len(cookies) != 3 {
+		t.Fatalf("NewChunked() returned %d cookies, want 3", len(cookies))
+	}
+
+	stale := cookies[2]
+	if stale.Name != "token.2" || stale.MaxAge != -1 || stale.Value != "" {
+		t.Errorf("stale chunk = %+v, want expired token.2", stale)
+	}
+	if stale.Path != "/app" || stale.Domain != "example.com" {
+		t.Errorf("stale chunk Path/Domain = %q/%q, want %q/%q", stale.Path, stale.Domain, "/app", "example.com")
+	}
+}
+
+func TestNewChunked_Errors(t *testing.T) {
+	if _, err := NewChunked(nil, "", "value", &Config{}); !errors.Is(err, ErrMissingName) {
+		t.Errorf("NewChunked() without name error = %v, want %v", err, ErrMissingName)
+	}
+
+	_, err := NewChunked(nil, "token", "abcdefghij", &Config{}, WithChunkSize(2), WithMaxChunks(3))
+	if !errors.Is(err, ErrTooManyChunks) {
+		t.Errorf("NewChunked() error = %v, want %v", err, ErrTooManyChunks)
+	}
+
+	_, err = NewChunked(nil, "token", strings.Repeat("x", 17), &Config{}, WithChunkSize(2))
+	if !errors.Is(err, ErrTooManyChunks) {
+		t.Errorf("NewChunked() with count prefix exceeding the chunk size error = %v, want %v", err, ErrTooManyChunks)
+	}
+}
+
+func TestReadChunked_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cookies []*http.Cookie
+		opts    []ChunkOption
+		wantErr error
+	}{
+		{name: "no cookie", wantErr: http.ErrNoCookie},
+		{
+			name:    "missing count",
+			cookies: []*http.Cookie{{Name: "token.0", Value: "abcd"}},
+			wantErr: ErrMalformedValue,
+		},
+		{
+			name:    "invalid count",
+			cookies: []*http.Cookie{{Name: "token.0", Value: "0:abcd"}},
+			wantErr: ErrMalformedValue,
+		},
+		{
+			name: "missing chunk",
+			cookies: []*http.Cookie{
+				{Name: "token.0", Value: "3:ab"},
+				{Name: "token.2", Value: "ef"},
+			},
+			wantErr: ErrMissingChunk,
+		},
+		{
+			name: "too many chunks",
+			cookies: []*http.Cookie{
+				{Name: "token.0", Value: "3:ab"},
+				{Name: "token.1", Value: "cd"},
+				{Name: "token.2", Value: "ef"},
+			},
+			opts:    []ChunkOption{WithMaxChunks(2)},
+			wantErr: ErrTooManyChunks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadChunked(requestWith(tt.cookies...), "token", tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadChunked() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != "" {
+				t.Errorf("ReadChunked() = %q, want empty value on error", got)
+			}
+		})
+	}
+}
+
+func TestChunked_WithCodec(t *testing.T) {
+	codec, err := NewEncryptedCodec([][]byte{testKey(1, 32)})
+	if err != nil {
+		t.Fatalf("NewEncryptedCodec() error = %v", err)
+	}
+
+	value := strings.Repeat("secret-claims-", 600)
+	config := &Config{Name: "id_token", Path: "/"}
+
+	cookies, err := NewChunked(nil, "", value, config, WithChunkCodec(codec))
+	if err != nil {
+		t.Fatalf("NewChunked() error = %v", err)
+	}
+
+	if len(cookies) < 2 {
+		t.Fatalf("NewChunked() returned %d cookies, want more than one", len(cookies))
+	}
+
+	got, err := ReadChunked(requestWith(cookies...), "id_token", WithChunkCodec(codec))
+	if err != nil {
+		t.Fatalf("ReadChunked() error = %v", err)
+	}
+	if got != value {
+		t.Error("ReadChunked() did not return the original value")
+	}
+
+	// a missing chunk must fail instead of returning a partial value
+	_, err = ReadChunked(requestWith(cookies[0]), "id_token", WithChunkCodec(codec))
+	if !errors.Is(err, ErrMissingChunk) {
+		t.Errorf("ReadChunked() with missing chunk error = %v, want %v", err, ErrMissingChunk)
+	}
+}
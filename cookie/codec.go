@@ -0,0 +1,334 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// MinSigningKeyLength is the minimum length in bytes of an HMAC signing key.
+	MinSigningKeyLength = 32
+
+	// timestampSize is the size of the embedded Unix timestamp in bytes.
+	timestampSize = 8
+)
+
+var (
+	// ErrMissingName indicates that neither a name nor Config.Name was provided.
+	ErrMissingName = errors.New("cookie: name is required")
+
+	// ErrNoKeys indicates that a codec was created without any keys.
+	ErrNoKeys = errors.New("cookie: at least one key is required")
+
+	// ErrInvalidKey indicates that a key has an unsupported length.
+	ErrInvalidKey = errors.New("cookie: invalid key length")
+
+	// ErrMalformedValue indicates that a cookie value is not in the expected encoding.
+	ErrMalformedValue = errors.New("cookie: malformed value")
+
+	// ErrInvalidSignature indicates that no key could verify the cookie signature.
+	ErrInvalidSignature = errors.New("cookie: invalid signature")
+
+	// ErrDecryptionFailed indicates that no key could decrypt and authenticate the cookie value.
+	ErrDecryptionFailed = errors.New("cookie: decryption failed")
+
+	// ErrExpired indicates that the embedded timestamp is older than the codec's max age.
+	ErrExpired = errors.New("cookie: value expired")
+)
+
+// Codec encodes and decodes cookie values.
+//
+// The cookie name is bound to the encoded value, so a value issued for one
+// cookie cannot be replayed under a different name.
+type Codec interface {
+	// Encode returns the encoded representation of value for the named cookie.
+	Encode(name, value string) (string, error)
+
+	// Decode verifies and decodes a value previously produced by Encode.
+	// Errors are of type *DecodeError.
+	Decode(name, value string) (string, error)
+}
+
+// DecodeError describes why a cookie value could not be decoded.
+//
+// Use errors.Is with ErrMalformedValue, ErrInvalidSignature,
+// ErrDecryptionFailed or ErrExpired to determine the reason.
+type DecodeError struct {
+	// Name is the name of the cookie that failed to decode.
+	Name string
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message including the cookie name.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode cookie %q: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying reason for errors.Is and errors.As.
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// CodecOption configures a SignedCodec or EncryptedCodec.
+type CodecOption func(*codecOptions)
+
+type codecOptions struct {
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// WithMaxAge rejects values whose embedded timestamp is older than maxAge.
+// A zero or negative maxAge disables the check.
+func WithMaxAge(maxAge time.Duration) CodecOption {
+	return func(o *codecOptions) {
+		o.maxAge = maxAge
+	}
+}
+
+func newCodecOptions(opts []CodecOption) codecOptions {
+	o := codecOptions{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// SignedCodec signs cookie values with HMAC-SHA256.
+//
+// Signed values are readable by the client but cannot be modified. The
+// encoded format is base64url(timestamp || value) "." base64url(mac).
+//
+// Keys are ordered from newest to oldest: the first key signs, all keys
+// verify. To rotate keys, prepend the new key and drop the oldest one once
+// all values signed with it have expired.
+type SignedCodec struct {
+	keys [][]byte
+	opts codecOptions
+}
+
+// NewSignedCodec creates a SignedCodec. Each key must be at least
+// MinSigningKeyLength bytes long.
+//
+// Example:
+//
+//	codec, err := cookie.NewSignedCodec(
+//		[][]byte{newKey, oldKey},
+//		cookie.WithMaxAge(time.Hour),
+//	)
+func NewSignedCodec(keys [][]byte, opts ...CodecOption) (*SignedCodec, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	for i, k := range keys {
+		if len(k) < MinSigningKeyLength {
+			return nil, fmt.Errorf("%w: signing key %d must be at least %d bytes", ErrInvalidKey, i, MinSigningKeyLength)
+		}
+	}
+
+	return &SignedCodec{
+		keys: keys,
+		opts: newCodecOptions(opts),
+	}, nil
+}
+
+// Encode signs value for the named cookie with the newest key.
+func (c *SignedCodec) Encode(name, value string) (string, error) {
+	payload := base64.RawURLEncoding.EncodeToString(withTimestamp(c.opts.now(), value))
+	mac := sign(c.keys[0], name, payload)
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+// Decode verifies the signature with any of the configured keys and returns
+// the original value.
+func (c *SignedCodec) Decode(name, value string) (string, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", &DecodeError{Name: name, Err: ErrMalformedValue}
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return "", &DecodeError{Name: name, Err: ErrMalformedValue}
+	}
+
+	verified := false
+	for _, key := range c.keys {
+		if hmac.Equal(mac, sign(key, name, payload)) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return "", &DecodeError{Name: name, Err: ErrInvalidSignature}
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", &DecodeError{Name: name, Err: ErrMalformedValue}
+	}
+
+	return c.opts.verifyTimestamp(name, data)
+}
+
+// EncryptedCodec encrypts and authenticates cookie values with AES-GCM.
+//
+// Encrypted values are neither readable nor modifiable by the client. The
+// encoded format is base64url(nonce || ciphertext), with the cookie name used
+// as additional authenticated data.
+//
+// Keys are ordered from newest to oldest: the first key encrypts, all keys
+// decrypt. Keys must be 16, 24 or 32 bytes long to select AES-128, AES-192
+// or AES-256.
+type EncryptedCodec struct {
+	aeads []cipher.AEAD
+	opts  codecOptions
+}
+
+// NewEncryptedCodec creates an EncryptedCodec.
+//
+// Example:
+//
+//	codec, err := cookie.NewEncryptedCodec(
+//		[][]byte{newKey, oldKey}, // 32 bytes each for AES-256
+//		cookie.WithMaxAge(24*time.Hour),
+//	)
+func NewEncryptedCodec(keys [][]byte, opts ...CodecOption) (*EncryptedCodec, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	aeads := make([]cipher.AEAD, len(keys))
+	for i, k := range keys {
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encryption key %d must be 16, 24 or 32 bytes", ErrInvalidKey, i)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		aeads[i] = aead
+	}
+
+	return &EncryptedCodec{
+		aeads: aeads,
+		opts:  newCodecOptions(opts),
+	}, nil
+}
+
+// Encode encrypts value for the named cookie with the newest key.
+func (c *EncryptedCodec) Encode(name, value string) (string, error) {
+	aead := c.aeads[0]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, withTimestamp(c.opts.now(), value), []byte(name))
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decode decrypts the value with any of the configured keys and returns the
+// original value.
+func (c *EncryptedCodec) Decode(name, value string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", &DecodeError{Name: name, Err: ErrMalformedValue}
+	}
+
+	for _, aead := range c.aeads {
+		nonceSize := aead.NonceSize()
+		if len(data) < nonceSize+aead.Overhead() {
+			return "", &DecodeError{Name: name, Err: ErrMalformedValue}
+		}
+
+		plain, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(name))
+		if err == nil {
+			return c.opts.verifyTimestamp(name, plain)
+		}
+	}
+
+	return "", &DecodeError{Name: name, Err: ErrDecryptionFailed}
+}
+
+// NewEncoded returns a new http.Cookie like New, with the value encoded by codec.
+//
+// Example:
+//
+//	c, err := cookie.NewEncoded("prefs", `{"theme":"dark"}`, config, codec)
+//	if err != nil {
+//		return err
+//	}
+//	http.SetCookie(w, c)
+//
+// Returns an error if no cookie name can be determined or encoding fails.
+func NewEncoded(name, value string, config *Config, codec Codec) (*http.Cookie, error) {
+	cookieName := name
+	if cookieName == "" {
+		cookieName = config.Name
+	}
+
+	if cookieName == "" {
+		return nil, ErrMissingName
+	}
+
+	encoded, err := codec.Encode(cookieName, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(cookieName, encoded, config), nil
+}
+
+// sign computes the HMAC-SHA256 of the cookie name and payload.
+func sign(key []byte, name, payload string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(name))
+	h.Write([]byte{'|'})
+	h.Write([]byte(payload))
+
+	return h.Sum(nil)
+}
+
+// withTimestamp prefixes value with the big-endian Unix timestamp of t.
+func withTimestamp(t time.Time, value string) []byte {
+	b := make([]byte, timestampSize, timestampSize+len(value))
+	binary.BigEndian.PutUint64(b, uint64(t.Unix())) //nolint:gosec // timestamps are positive
+	return append(b, value...)
+}
+
+// verifyTimestamp strips the embedded timestamp and enforces the max age.
+func (o codecOptions) verifyTimestamp(name string, data []byte) (string, error) {
+	if len(data) < timestampSize {
+		return "", &DecodeError{Name: name, Err: ErrMalformedValue}
+	}
+
+	if o.maxAge > 0 {
+		issued := time.Unix(int64(binary.BigEndian.Uint64(data[:timestampSize])), 0) //nolint:gosec // verified payload
+		if o.now().Sub(issued) > o.maxAge {
+			return "", &DecodeError{Name: name, Err: ErrExpired}
+		}
+	}
+
+	return string(data[timestampSize:]), nil
+}
@@ -0,0 +1,217 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(b byte, n int) []byte {
+	return bytes.Repeat([]byte{b}, n)
+}
+
+func newTestCodecs(t *testing.T, keys [][]byte, opts ...CodecOption) map[string]Codec {
+	t.Helper()
+
+	signed, err := NewSignedCodec(keys, opts...)
+	if err != nil {
+		t.Fatalf("NewSignedCodec() error = %v", err)
+	}
+
+	encrypted, err := NewEncryptedCodec(keys, opts...)
+	if err != nil {
+		t.Fatalf("NewEncryptedCodec() error = %v", err)
+	}
+
+	return map[string]Codec{"signed": signed, "encrypted": encrypted}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codecs := newTestCodecs(t, [][]byte{testKey(1, 32)})
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			for _, value := range []string{"", "token", `{"theme":"dark"}`, strings.Repeat("x", 1024)} {
+				encoded, err := codec.Encode("prefs", value)
+				if err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+
+				if value != "" && name == "encrypted" && strings.Contains(encoded, value) {
+					t.Errorf("Encode() leaks plaintext: %q", encoded)
+				}
+
+				decoded, err := codec.Decode("prefs", encoded)
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+
+				if decoded != value {
+					t.Errorf("Decode() = %q, want %q", decoded, value)
+				}
+			}
+		})
+	}
+}
+
+func TestCodec_KeyRotation(t *testing.T) {
+	oldKey, newKey := testKey(1, 32), testKey(2, 32)
+
+	for name, oldCodec := range newTestCodecs(t, [][]byte{oldKey}) {
+		t.Run(name, func(t *testing.T) {
+			rotated := newTestCodecs(t, [][]byte{newKey, oldKey})[name]
+			retired := newTestCodecs(t, [][]byte{newKey})[name]
+
+			encoded, err := oldCodec.Encode("csrf", "value")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			if got, err := rotated.Decode("csrf", encoded); err != nil || got != "value" {
+				t.Errorf("rotated Decode() = %q, %v; want %q, nil", got, err, "value")
+			}
+
+			if _, err := retired.Decode("csrf", encoded); err == nil {
+				t.Error("Decode() with retired key should fail")
+			}
+
+			encoded, err = rotated.Encode("csrf", "value")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			if _, err := retired.Decode("csrf", encoded); err != nil {
+				t.Errorf("newest key should be used for encoding, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCodec_DecodeErrors(t *testing.T) {
+	for name, codec := range newTestCodecs(t, [][]byte{testKey(1, 32)}) {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := codec.Encode("csrf", "value")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			wantTampered := ErrInvalidSignature
+			if name == "encrypted" {
+				wantTampered = ErrDecryptionFailed
+			}
+
+			// replace a character with another valid base64url character
+			tampered := []byte(encoded)
+			if tampered[2] == 'A' {
+				tampered[2] = 'B'
+			} else {
+				tampered[2] = 'A'
+			}
+
+			tests := []struct {
+				name   string
+				cookie string
+				value  string
+				want   error
+			}{
+				{name: "wrong cookie name", cookie: "other", value: encoded, want: wantTampered},
+				{name: "tampered value", cookie: "csrf", value: string(tampered), want: wantTampered},
+				{name: "not base64", cookie: "csrf", value: "!!!.!!!", want: ErrMalformedValue},
+				{name: "empty", cookie: "csrf", value: "", want: ErrMalformedValue},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					_, err := codec.Decode(tt.cookie, tt.value)
+					if !errors.Is(err, tt.want) {
+						t.Fatalf("Decode() error = %v, want %v", err, tt.want)
+					}
+
+					var decodeErr *DecodeError
+					if !errors.As(err, &decodeErr) {
+						t.Fatalf("Decode() error type = %T, want *DecodeError", err)
+					}
+
+					if decodeErr.Name != tt.cookie {
+						t.Errorf("DecodeError.Name = %q, want %q", decodeErr.Name, tt.cookie)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestCodec_MaxAge(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func(o *codecOptions) { o.now = func() time.Time { return now } }
+
+	for name, codec := range newTestCodecs(t, [][]byte{testKey(1, 32)}, WithMaxAge(time.Minute), clock) {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := codec.Encode("csrf", "value")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			now = now.Add(30 * time.Second)
+			if _, err := codec.Decode("csrf", encoded); err != nil {
+				t.Errorf("Decode() within max age error = %v", err)
+			}
+
+			now = now.Add(time.Minute)
+			if _, err := codec.Decode("csrf", encoded); !errors.Is(err, ErrExpired) {
+				t.Errorf("Decode() after max age error = %v, want %v", err, ErrExpired)
+			}
+
+			now = now.Add(-90 * time.Second)
+		})
+	}
+}
+
+func TestCodec_InvalidKeys(t *testing.T) {
+	if _, err := NewSignedCodec(nil); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("NewSignedCodec(nil) error = %v, want %v", err, ErrNoKeys)
+	}
+
+	if _, err := NewSignedCodec([][]byte{testKey(1, 16)}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewSignedCodec(short key) error = %v, want %v", err, ErrInvalidKey)
+	}
+
+	if _, err := NewEncryptedCodec(nil); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("NewEncryptedCodec(nil) error = %v, want %v", err, ErrNoKeys)
+	}
+
+	if _, err := NewEncryptedCodec([][]byte{testKey(1, 20)}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewEncryptedCodec(20 byte key) error = %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestNewEncoded(t *testing.T) {
+	codec, err := NewSignedCodec([][]byte{testKey(1, 32)})
+	if err != nil {
+		t.Fatalf("NewSignedCodec() error = %v", err)
+	}
+
+	config := &Config{Name: "prefs", Path: "/", HTTPOnly: true}
+
+	c, err := NewEncoded("", "dark", config, codec)
+	if err != nil {
+		t.Fatalf("NewEncoded() error = %v", err)
+	}
+
+	if c.Name != "prefs" || c.Path != "/" || !c.HttpOnly {
+		t.Errorf("NewEncoded() = %+v, config not applied", c)
+	}
+
+	if got, err := codec.Decode(c.Name, c.Value); err != nil || got != "dark" {
+		t.Errorf("Decode() = %q, %v; want %q, nil", got, err, "dark")
+	}
+
+	if _, err := NewEncoded("", "dark", &Config{}, codec); !errors.Is(err, ErrMissingName) {
+		t.Errorf("NewEncoded() without name error = %v, want %v", err, ErrMissingName)
+	}
+}
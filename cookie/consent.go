@@ -0,0 +1,313 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kopexa-grc/x/echolog"
+	"github.com/labstack/echo/v4"
+)
+
+// Category classifies a cookie for consent purposes.
+type Category string
+
+const (
+	// CategoryEssential marks cookies strictly necessary for the service.
+	// Essential cookies never require consent. An empty Category is treated
+	// as essential.
+	CategoryEssential Category = "essential"
+
+	// CategoryFunctional marks cookies that remember user preferences.
+	CategoryFunctional Category = "functional"
+
+	// CategoryAnalytics marks cookies used for statistics and measurement.
+	CategoryAnalytics Category = "analytics"
+
+	// CategoryMarketing marks cookies used for advertising and tracking.
+	CategoryMarketing Category = "marketing"
+)
+
+// DefaultConsentCookieName is the consent cookie name used when
+// PolicyConfig.ConsentCookie.Name is empty.
+const DefaultConsentCookieName = "cookie_consent"
+
+// ErrConsentRequired indicates that a cookie was refused because the user
+// has not consented to its category.
+var ErrConsentRequired = errors.New("cookie: consent required")
+
+// Consent is the set of cookie categories a user has consented to.
+// Essential cookies are always allowed.
+type Consent map[Category]bool
+
+// ParseConsent parses a comma-separated list of categories as stored in the
+// consent cookie, e.g. "functional,analytics". Unknown categories are ignored.
+func ParseConsent(value string) Consent {
+	consent := Consent{}
+
+	for _, part := range strings.Split(value, ",") {
+		switch category := Category(strings.TrimSpace(part)); category {
+		case CategoryFunctional, CategoryAnalytics, CategoryMarketing:
+			consent[category] = true
+		}
+	}
+
+	return consent
+}
+
+// Allows reports whether cookies of the given category may be set.
+func (c Consent) Allows(category Category) bool {
+	if category == "" || category == CategoryEssential {
+		return true
+	}
+
+	return c[category]
+}
+
+// String returns the consent in the format read by ParseConsent.
+func (c Consent) String() string {
+	categories := make([]string, 0, len(c))
+	for _, category := range []Category{CategoryFunctional, CategoryAnalytics, CategoryMarketing} {
+		if c[category] {
+			categories = append(categories, string(category))
+		}
+	}
+
+	return strings.Join(categories, ",")
+}
+
+// BlockedCookie describes a cookie that was refused or stripped by a Policy.
+type BlockedCookie struct {
+	// Name is the cookie name.
+	Name string
+
+	// Category is the category that lacked consent.
+	Category Category
+}
+
+// PolicyConfig configures a Policy.
+type PolicyConfig struct {
+	// ConsentCookie configures the cookie storing the user's consent.
+	// ConsentCookie.Name defaults to DefaultConsentCookieName. The consent
+	// cookie itself is always treated as essential.
+	ConsentCookie Config
+
+	// Cookies registers the configs of all cookies used by the application,
+	// so that the policy can determine the category of cookies on the response.
+	Cookies []*Config
+
+	// DefaultCategory is assigned to cookies that are not registered.
+	// Defaults to CategoryEssential.
+	DefaultCategory Category
+
+	// OnBlocked is called with the cookies that were refused or stripped for a
+	// request, e.g. for audit logging. If nil, blocked cookies are logged at
+	// info level with the request-scoped echolog logger.
+	OnBlocked func(c echo.Context, blocked []BlockedCookie)
+}
+
+// Policy enforces cookie consent based on cookie categories.
+//
+// Example:
+//
+//	prefs := &cookie.Config{Name: "prefs", Path: "/", Category: cookie.CategoryFunctional}
+//	tracking := &cookie.Config{Name: "_ga", Path: "/", Category: cookie.CategoryAnalytics}
+//
+//	policy := cookie.NewPolicy(cookie.PolicyConfig{
+//		ConsentCookie: *cookie.SecureDefaults("cookie_consent"),
+//		Cookies:       []*cookie.Config{prefs, tracking},
+//	})
+//
+//	e.Use(policy.Middleware())
+type Policy struct {
+	config     PolicyConfig
+	categories map[string]Category
+}
+
+// NewPolicy creates a new Policy.
+func NewPolicy(config PolicyConfig) *Policy {
+	if config.ConsentCookie.Name == "" {
+		config.ConsentCookie.Name = DefaultConsentCookieName
+	}
+
+	if config.DefaultCategory == "" {
+		config.DefaultCategory = CategoryEssential
+	}
+
+	p := &Policy{
+		config:     config,
+		categories: make(map[string]Category, len(config.Cookies)),
+	}
+
+	for _, c := range config.Cookies {
+		if c != nil && c.Name != "" {
+			p.categories[c.Name] = c.Category
+		}
+	}
+
+	return p
+}
+
+// Consent returns the consent stored in the request's consent cookie.
+// Without a consent cookie only essential cookies are allowed.
+func (p *Policy) Consent(r *http.Request) Consent {
+	ck, err := r.Cookie(p.config.ConsentCookie.Name)
+	if err != nil {
+		return Consent{}
+	}
+
+	return ParseConsent(ck.Value)
+}
+
+// Category returns the category of the named cookie. Chunks written by
+// NewChunked ("name.0", "name.1", ...) have the category of the base cookie.
+func (p *Policy) Category(name string) Category {
+	if name == p.config.ConsentCookie.Name {
+		return CategoryEssential
+	}
+
+	if category, ok := p.categories[name]; ok {
+		return category
+	}
+
+	if base, ok := chunkBase(name); ok {
+		if category, ok := p.categories[base]; ok {
+			return category
+		}
+	}
+
+	return p.config.DefaultCategory
+}
+
+// currentConsent returns the consent last written to the response, e.g. by
+// SetConsent, or the consent from the request.
+func (p *Policy) currentConsent(c echo.Context) Consent {
+	values := c.Response().Header().Values(echo.HeaderSetCookie)
+	for i := len(values) - 1; i >= 0; i-- {
+		ck, err := http.ParseSetCookie(values[i])
+		if err != nil || ck.Name != p.config.ConsentCookie.Name {
+			continue
+		}
+
+		if isDeletion(ck, time.Now()) {
+			return Consent{}
+		}
+
+		return ParseConsent(ck.Value)
+	}
+
+	return p.Consent(c.Request())
+}
+
+// SetConsent stores the consent in the consent cookie on the Echo response.
+func (p *Policy) SetConsent(c echo.Context, consent Consent) error {
+	return Set(c, "", consent.String(), &p.config.ConsentCookie)
+}
+
+// Set writes the cookie like Set, but refuses it with ErrConsentRequired if
+// there is no consent for config.Category. Consent written to the response
+// with SetConsent takes precedence over the request's consent cookie. Refused cookies are
+// reported to PolicyConfig.OnBlocked.
+func (p *Policy) Set(c echo.Context, name, value string, config *Config) error {
+	if name == "" {
+		name = config.Name
+	}
+
+	if !p.currentConsent(c).Allows(config.Category) {
+		p.report(c, []BlockedCookie{{Name: name, Category: config.Category}})
+		return ErrConsentRequired
+	}
+
+	return Set(c, name, value, config)
+}
+
+// Middleware returns an Echo middleware that strips cookies without consent
+// from the response right before the headers are written.
+//
+// The category of each Set-Cookie header is determined by name from the
+// registered configs. Deletion cookies are always passed through, so that
+// cookies can be removed after consent is withdrawn.
+func (p *Policy) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Before(func() {
+				p.strip(c)
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// strip removes Set-Cookie headers without consent from the response.
+func (p *Policy) strip(c echo.Context) {
+	header := c.Response().Header()
+
+	values := header.Values(echo.HeaderSetCookie)
+	if len(values) == 0 {
+		return
+	}
+
+	consent := p.currentConsent(c)
+	now := time.Now()
+
+	var blocked []BlockedCookie
+
+	kept := slices.DeleteFunc(slices.Clone(values), func(v string) bool {
+		ck, err := http.ParseSetCookie(v)
+		if err != nil {
+			return false
+		}
+
+		if isDeletion(ck, now) {
+			return false
+		}
+
+		category := p.Category(ck.Name)
+		if consent.Allows(category) {
+			return false
+		}
+
+		blocked = append(blocked, BlockedCookie{Name: ck.Name, Category: category})
+
+		return true
+	})
+
+	if len(blocked) == 0 {
+		return
+	}
+
+	header.Del(echo.HeaderSetCookie)
+	for _, v := range kept {
+		header.Add(echo.HeaderSetCookie, v)
+	}
+
+	p.report(c, blocked)
+}
+
+// isDeletion reports whether the Set-Cookie ck removes the cookie.
+func isDeletion(ck *http.Cookie, now time.Time) bool {
+	return ck.MaxAge < 0 || (!ck.Expires.IsZero() && ck.Expires.Before(now))
+}
+
+// report passes blocked cookies to the configured handler or logs them.
+func (p *Policy) report(c echo.Context, blocked []BlockedCookie) {
+	if p.config.OnBlocked != nil {
+		p.config.OnBlocked(c, blocked)
+		return
+	}
+
+	logger := echolog.Ctx(c.Request().Context())
+	for _, b := range blocked {
+		logger.Info().
+			Str("cookie", b.Name).
+			Str("category", string(b.Category)).
+			Str("uri", c.Request().RequestURI).
+			Msg("cookie blocked without consent")
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/kopexa-grc/x/echolog"
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseConsent(t *testing.T) {
+	consent := ParseConsent(" functional, marketing,unknown,,essential")
+
+	tests := []struct {
+		category Category
+		want     bool
+	}{
+		{"", true},
+		{CategoryEssential, true},
+		{CategoryFunctional, true},
+		{CategoryAnalytics, false},
+		{CategoryMarketing, true},
+	}
+
+	for _, tt := range tests {
+		if got := consent.Allows(tt.category); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.category, got, tt.want)
+		}
+	}
+
+	if got := consent.String(); got != "functional,marketing" {
+		t.Errorf("String() = %q, want %q", got, "functional,marketing")
+	}
+
+	if got := ParseConsent(consent.String()); len(got) != len(consent) {
+		t.Errorf("ParseConsent(String()) = %v, want %v", got, consent)
+	}
+}
+
+func newTestPolicy(blocked *[]BlockedCookie) (*Policy, *Config, *Config) {
+	prefs := &Config{Name: "prefs", Path: "/", Category: CategoryFunctional}
+	tracking := &Config{Name: "_ga", Path: "/", Category: CategoryAnalytics}
+
+	policy := NewPolicy(PolicyConfig{
+		Cookies: []*Config{prefs, tracking},
+		OnBlocked: func(_ echo.Context, b []BlockedCookie) {
+			*blocked = append(*blocked, b...)
+		},
+	})
+
+	return policy, prefs, tracking
+}
+
+func TestPolicy_Category(t *testing.T) {
+	policy, prefs, tracking := newTestPolicy(nil)
+
+	tests := map[string]Category{
+		DefaultConsentCookieName:    CategoryEssential,
+		prefs.Name:                  CategoryFunctional,
+		tracking.Name:               CategoryAnalytics,
+		ChunkName(tracking.Name, 0): CategoryAnalytics,
+		ChunkName(tracking.Name, 3): CategoryAnalytics,
+		tracking.Name + ".x":        CategoryEssential,
+		tracking.Name + ".-1":       CategoryEssential,
+		"unknown.0":                 CategoryEssential,
+	}
+
+	for name, want := range tests {
+		if got := policy.Category(name); got != want {
+			t.Errorf("Category(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPolicy_Set(t *testing.T) {
+	var blocked []BlockedCookie
+	policy, prefs, tracking := newTestPolicy(&blocked)
+
+	c, rec := newEchoContext(&http.Cookie{Name: DefaultConsentCookieName, Value: "functional"})
+
+	if err := policy.Set(c, "", "dark", prefs); err != nil {
+		t.Errorf("Set() consented error = %v", err)
+	}
+
+	if err := policy.Set(c, "", "GA1.2", tracking); !errors.Is(err, ErrConsentRequired) {
+		t.Errorf("Set() without consent error = %v, want %v", err, ErrConsentRequired)
+	}
+
+	if err := policy.Set(c, "session", "abc", &Config{}); err != nil {
+		t.Errorf("Set() essential error = %v", err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Name != "prefs" || cookies[1].Name != "session" {
+		t.Errorf("cookies = %+v, want prefs and session", cookies)
+	}
+
+	if len(blocked) != 1 || blocked[0].Name != "_ga" || blocked[0].Category != CategoryAnalytics {
+		t.Errorf("blocked = %+v, want _ga", blocked)
+	}
+}
+
+func TestPolicy_Middleware(t *testing.T) {
+	var blocked []BlockedCookie
+	policy, prefs, tracking := newTestPolicy(&blocked)
+
+	e := echo.New()
+	e.Use(policy.Middleware())
+	e.GET("/", func(c echo.Context) error {
+		c.SetCookie(New("", "dark", prefs))
+		c.SetCookie(New("", "GA1.2", tracking))
+		c.SetCookie(Expire("_ga_old", tracking))
+		c.SetCookie(&http.Cookie{Name: "session", Value: "abc"})
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/consent", func(c echo.Context) error {
+		return policy.SetConsent(c, Consent{CategoryAnalytics: true})
+	})
+	e.POST("/consent/track", func(c echo.Context) error {
+		if err := policy.SetConsent(c, Consent{CategoryAnalytics: true}); err != nil {
+			return err
+		}
+		if err := policy.Set(c, "", "GA1.2", tracking); err != nil {
+			return err
+		}
+		c.SetCookie(New("", "dark", prefs))
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/consent/withdraw", func(c echo.Context) error {
+		c.SetCookie(Expire(DefaultConsentCookieName, &Config{}))
+		c.SetCookie(New("", "dark", prefs))
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		consent     string
+		wantCookies []string
+		wantBlocked []string
+	}{
+		{
+			name:        "no consent",
+			wantCookies: []string{"_ga_old", "session"},
+			wantBlocked: []string{"prefs", "_ga"},
+		},
+		{
+			name:        "functional consent",
+			consent:     "functional",
+			wantCookies: []string{"prefs", "_ga_old", "session"},
+			wantBlocked: []string{"_ga"},
+		},
+		{
+			name:        "full consent",
+			consent:     "functional,analytics,marketing",
+			wantCookies: []string{"prefs", "_ga", "_ga_old", "session"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.consent != "" {
+				req.AddCookie(&http.Cookie{Name: DefaultConsentCookieName, Value: tt.consent})
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var names []string
+			for _, ck := range rec.Result().Cookies() {
+				names = append(names, ck.Name)
+			}
+			if !slices.Equal(names, tt.wantCookies) {
+				t.Errorf("cookies = %v, want %v", names, tt.wantCookies)
+			}
+
+			var blockedNames []string
+			for _, b := range blocked {
+				blockedNames = append(blockedNames, b.Name)
+			}
+			if !slices.Equal(blockedNames, tt.wantBlocked) {
+				t.Errorf("blocked = %v, want %v", blockedNames, tt.wantBlocked)
+			}
+		})
+	}
+
+	t.Run("consent set in the same response", func(t *testing.T) {
+		blocked = nil
+
+		req := httptest.NewRequest(http.MethodPost, "/consent/track", nil)
+		req.AddCookie(&http.Cookie{Name: DefaultConsentCookieName, Value: "functional"})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var names []string
+		for _, ck := range rec.Result().Cookies() {
+			names = append(names, ck.Name)
+		}
+		if want := []string{DefaultConsentCookieName, "_ga"}; !slices.Equal(names, want) {
+			t.Errorf("cookies = %v, want %v", names, want)
+		}
+		if len(blocked) != 1 || blocked[0].Name != "prefs" {
+			t.Errorf("blocked = %+v, want prefs", blocked)
+		}
+	})
+
+	t.Run("consent withdrawn in the same response", func(t *testing.T) {
+		blocked = nil
+
+		req := httptest.NewRequest(http.MethodPost, "/consent/withdraw", nil)
+		req.AddCookie(&http.Cookie{Name: DefaultConsentCookieName, Value: "functional"})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if len(blocked) != 1 || blocked[0].Name != "prefs" {
+			t.Errorf("blocked = %+v, want prefs", blocked)
+		}
+	})
+
+	t.Run("consent cookie is essential", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/consent", nil))
+
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != DefaultConsentCookieName || cookies[0].Value != "analytics" {
+			t.Errorf("cookies = %+v, want consent cookie", cookies)
+		}
+	})
+}
+
+func TestPolicy_DefaultReportLogs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	policy := NewPolicy(PolicyConfig{DefaultCategory: CategoryMarketing})
+
+	e := echo.New()
+	e.Use(echolog.LoggingMiddleware(echolog.Config{Logger: echolog.New(buf)}))
+	e.Use(policy.Middleware())
+	e.GET("/", func(c echo.Context) error {
+		c.SetCookie(&http.Cookie{Name: "pixel", Value: "1"})
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if len(rec.Result().Cookies()) != 0 {
+		t.Errorf("unregistered cookie should use DefaultCategory and be stripped")
+	}
+
+	for _, want := range []string{`"cookie":"pixel"`, `"category":"marketing"`, `"request_id":"req-1"`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("log output %q does not contain %s", buf.String(), want)
+		}
+	}
+}
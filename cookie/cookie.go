@@ -56,6 +56,10 @@ type Config struct {
 	// SameSite controls whether the cookie is sent with cross-site requests.
 	// Use SameSiteLaxMode or SameSiteStrictMode to prevent CSRF attacks.
 	SameSite http.SameSite
+
+	// Category classifies the cookie for consent purposes.
+	// Leave empty for essential cookies. See Policy.
+	Category Category
 }
 
 // New returns a new http.Cookie with the given name, value, and properties from config.
@@ -26,6 +26,77 @@
 //
 //	cookie := cookie.New("session", "abc123", config)
 //
+// # Reading and Deleting
+//
+// Read returns a cookie from an *http.Request using the name from a Config.
+// Expire and Delete build a deletion cookie from the same Config that created
+// the cookie, so Domain and Path match and the browser actually removes it.
+// For Echo applications, Set, Get and Clear operate on echo.Context, and a
+// Jar groups all cookie configs of an application:
+//
+//	jar := cookie.NewJar(sessionConfig, prefsConfig)
+//	jar.Set(c, "prefs", "dark")
+//	jar.Clear(c) // logout: expire every cookie in the jar
+//
+// # Validation
+//
+// New accepts any configuration. NewValidated additionally rejects attribute
+// combinations that browsers refuse, such as __Host- cookies with a Domain or
+// a Path other than "/", __Secure- cookies without Secure, or SameSite=None
+// without Secure, and checks the name and value against RFC 6265.
+// SecureDefaults returns a profile that satisfies all of these rules:
+//
+//	c, err := cookie.NewValidated(cookie.HostPrefix+"session", token, cookie.SecureDefaults(""))
+//
+// # Signed and Encrypted Values
+//
+// Cookie values can be protected with a Codec. SignedCodec (HMAC-SHA256)
+// prevents tampering, EncryptedCodec (AES-GCM) additionally hides the value
+// from the client. Both bind the value to the cookie name, embed the issue
+// time and support key rotation: the first key encodes, all keys decode.
+//
+//	codec, err := cookie.NewEncryptedCodec([][]byte{newKey, oldKey}, cookie.WithMaxAge(time.Hour))
+//	if err != nil {
+//		return err
+//	}
+//
+//	c, err := cookie.NewEncoded("prefs", `{"theme":"dark"}`, config, codec)
+//
+//	// later, when reading the request
+//	ck, err := r.Cookie("prefs")
+//	if err != nil {
+//		return err
+//	}
+//	value, err := codec.Decode("prefs", ck.Value)
+//	if errors.Is(err, cookie.ErrExpired) {
+//		// issue a new cookie
+//	}
+//
+// # Large Values
+//
+// Browsers limit cookies to about 4KB. NewChunked splits larger values across
+// numbered cookies ("name.0", "name.1", ...) and expires stale chunks left
+// over from a previously larger value; ReadChunked reassembles them:
+//
+//	cookies, err := cookie.NewChunked(r, "id_token", token, config, cookie.WithChunkCodec(codec))
+//	...
+//	token, err := cookie.ReadChunked(r, "id_token", cookie.WithChunkCodec(codec))
+//
+// The first chunk records the chunk count, so ReadChunked fails with
+// ErrMissingChunk instead of returning a truncated value.
+//
+// # Consent
+//
+// Config.Category classifies a cookie as essential, functional, analytics or
+// marketing. A Policy reads the user's consent from a consent cookie, refuses
+// non-consented cookies in Policy.Set and strips them from responses in
+// Policy.Middleware. Blocked cookies are reported for audit logging:
+//
+//	policy := cookie.NewPolicy(cookie.PolicyConfig{
+//		Cookies: []*cookie.Config{prefsConfig, analyticsConfig},
+//	})
+//	e.Use(policy.Middleware())
+//
 // # Security Considerations
 //
 // For production applications, it is recommended to:
@@ -44,4 +115,5 @@
 // - Secure: HTTPS-only transmission
 // - HTTPOnly: Prevents JavaScript access
 // - SameSite: Cross-site request behavior
+// - Category: Consent category (empty for essential cookies)
 package cookie
@@ -0,0 +1,147 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrUnknownName indicates that a cookie name is not registered in a Jar.
+var ErrUnknownName = errors.New("cookie: name not registered")
+
+// Set creates a cookie with New and writes it to the Echo response.
+//
+// If name is empty, config.Name is used. Returns ErrMissingName if no cookie
+// name can be determined.
+//
+// Example:
+//
+//	if err := cookie.Set(c, "", token, sessionConfig); err != nil {
+//		return err
+//	}
+func Set(c echo.Context, name, value string, config *Config) error {
+	ck := New(name, value, config)
+	if ck == nil {
+		return ErrMissingName
+	}
+
+	c.SetCookie(ck)
+
+	return nil
+}
+
+// Get returns the value of the named cookie from the Echo request.
+//
+// If name is empty, config.Name is used. Returns http.ErrNoCookie if the
+// cookie is not present.
+func Get(c echo.Context, name string, config *Config) (string, error) {
+	ck, err := Read(c.Request(), name, config)
+	if err != nil {
+		return "", err
+	}
+
+	return ck.Value, nil
+}
+
+// Clear expires the cookies described by configs on the Echo response, using
+// each config's Name, Domain and Path. Nil configs and configs without a
+// name are ignored.
+//
+// Example:
+//
+//	// logout: remove session, CSRF and preference cookies at once
+//	cookie.Clear(c, sessionConfig, csrfConfig, prefsConfig)
+func Clear(c echo.Context, configs ...*Config) {
+	for _, config := range configs {
+		if config == nil {
+			continue
+		}
+		if ck := Expire("", config); ck != nil {
+			c.SetCookie(ck)
+		}
+	}
+}
+
+// Jar groups the configurations of all cookies used by an application so
+// that they can be set, read and cleared by name.
+//
+// Example:
+//
+//	jar := cookie.NewJar(
+//		&cookie.Config{Name: "session", Path: "/", Secure: true, HTTPOnly: true},
+//		&cookie.Config{Name: "prefs", Path: "/", MaxAge: 31536000},
+//	)
+//
+//	jar.Set(c, "prefs", "dark")
+//	theme, err := jar.Get(c, "prefs")
+//	jar.Clear(c) // clears all cookies in the jar
+type Jar struct {
+	configs map[string]*Config
+	names   []string
+}
+
+// NewJar creates a Jar from the given configs, keyed by Config.Name.
+// Configs without a name are ignored; later configs replace earlier ones
+// with the same name.
+func NewJar(configs ...*Config) *Jar {
+	j := &Jar{configs: make(map[string]*Config, len(configs))}
+
+	for _, config := range configs {
+		if config == nil || config.Name == "" {
+			continue
+		}
+
+		if _, ok := j.configs[config.Name]; !ok {
+			j.names = append(j.names, config.Name)
+		}
+		j.configs[config.Name] = config
+	}
+
+	return j
+}
+
+// Config returns the configuration registered for name.
+func (j *Jar) Config(name string) (*Config, bool) {
+	config, ok := j.configs[name]
+	return config, ok
+}
+
+// Set writes the named cookie with its registered configuration.
+// Returns ErrUnknownName if name is not registered.
+func (j *Jar) Set(c echo.Context, name, value string) error {
+	config, ok := j.configs[name]
+	if !ok {
+		return ErrUnknownName
+	}
+
+	return Set(c, name, value, config)
+}
+
+// Get returns the value of the named cookie from the request.
+// Returns ErrUnknownName if name is not registered and http.ErrNoCookie if
+// the cookie is not present.
+func (j *Jar) Get(c echo.Context, name string) (string, error) {
+	config, ok := j.configs[name]
+	if !ok {
+		return "", ErrUnknownName
+	}
+
+	return Get(c, name, config)
+}
+
+// Clear expires the named cookies, or all cookies in the jar if no names are
+// given. Unknown names are ignored.
+func (j *Jar) Clear(c echo.Context, names ...string) {
+	if len(names) == 0 {
+		names = j.names
+	}
+
+	for _, name := range names {
+		if config, ok := j.configs[name]; ok {
+			Clear(c, config)
+		}
+	}
+}
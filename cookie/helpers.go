@@ -0,0 +1,54 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"net/http"
+)
+
+// Read returns the named cookie from the request.
+//
+// If name is empty, config.Name is used. Browsers only send the name and value
+// of a cookie, so the remaining attributes of config are not checked.
+//
+// Returns http.ErrNoCookie if the cookie is not present or no name can be determined.
+func Read(r *http.Request, name string, config *Config) (*http.Cookie, error) {
+	if name == "" {
+		name = config.Name
+	}
+
+	if name == "" {
+		return nil, http.ErrNoCookie
+	}
+
+	return r.Cookie(name)
+}
+
+// Expire returns a cookie that deletes the named cookie in the browser.
+//
+// Browsers only replace a cookie when name, Domain and Path match, which is
+// the usual reason why deletion silently fails. Expire therefore copies all
+// attributes from config, the same config used to create the cookie, and
+// only overrides the value and lifetime.
+//
+// If name is empty, config.Name is used. Returns nil if no cookie name can be
+// determined.
+//
+// Example:
+//
+//	http.SetCookie(w, cookie.Expire("", sessionConfig))
+func Expire(name string, config *Config) *http.Cookie {
+	expired := *config
+	expired.MaxAge = -1
+
+	return New(name, "", &expired)
+}
+
+// Delete writes a cookie to w that deletes the named cookie.
+// See Expire for details.
+func Delete(w http.ResponseWriter, name string, config *Config) {
+	if c := Expire(name, config); c != nil {
+		http.SetCookie(w, c)
+	}
+}
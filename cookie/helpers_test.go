@@ -0,0 +1,167 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRead(t *testing.T) {
+	config := &Config{Name: "session", Path: "/app"}
+	r := requestWith(&http.Cookie{Name: "session", Value: "abc"})
+
+	got, err := Read(r, "", config)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got.Value != "abc" {
+		t.Errorf("Read() value = %q, want %q", got.Value, "abc")
+	}
+
+	if _, err := Read(r, "missing", config); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("Read() missing error = %v, want %v", err, http.ErrNoCookie)
+	}
+
+	if _, err := Read(r, "", &Config{}); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("Read() without name error = %v, want %v", err, http.ErrNoCookie)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	config := &Config{
+		Name:     "session",
+		Domain:   "example.com",
+		Path:     "/app",
+		MaxAge:   3600,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+
+	got := Expire("", config)
+	if got == nil {
+		t.Fatal("Expire() returned nil")
+	}
+
+	if got.Name != "session" || got.Value != "" || got.MaxAge != -1 || !got.Expires.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expire() = %+v, want expired session cookie", got)
+	}
+
+	if got.Domain != config.Domain || got.Path != config.Path || got.Secure != config.Secure ||
+		got.HttpOnly != config.HTTPOnly || got.SameSite != config.SameSite {
+		t.Errorf("Expire() = %+v, attributes must match config", got)
+	}
+
+	if config.MaxAge != 3600 {
+		t.Error("Expire() must not modify config")
+	}
+
+	if Expire("", &Config{}) != nil {
+		t.Error("Expire() without name should return nil")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Delete(rec, "", &Config{Name: "session", Path: "/"})
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "session" || cookies[0].MaxAge != -1 {
+		t.Errorf("Delete() cookies = %+v, want expired session cookie", cookies)
+	}
+}
+
+func newEchoContext(cookies ...*http.Cookie) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(requestWith(cookies...), rec), rec
+}
+
+func TestEchoHelpers(t *testing.T) {
+	session := &Config{Name: "session", Path: "/", HTTPOnly: true}
+	prefs := &Config{Name: "prefs", Path: "/settings", Domain: "example.com"}
+
+	c, rec := newEchoContext(&http.Cookie{Name: "session", Value: "abc"})
+
+	if err := Set(c, "", "xyz", prefs); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := Set(c, "", "xyz", &Config{}); !errors.Is(err, ErrMissingName) {
+		t.Errorf("Set() without name error = %v, want %v", err, ErrMissingName)
+	}
+
+	if got, err := Get(c, "", session); err != nil || got != "abc" {
+		t.Errorf("Get() = %q, %v; want %q, nil", got, err, "abc")
+	}
+	if _, err := Get(c, "", prefs); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("Get() missing error = %v, want %v", err, http.ErrNoCookie)
+	}
+
+	Clear(c, session, nil, prefs, &Config{})
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 3 {
+		t.Fatalf("got %d cookies, want 3", len(cookies))
+	}
+
+	if cookies[0].Name != "prefs" || cookies[0].Value != "xyz" {
+		t.Errorf("Set() cookie = %+v", cookies[0])
+	}
+	if cookies[1].Name != "session" || cookies[1].MaxAge != -1 {
+		t.Errorf("Clear() cookie = %+v, want expired session", cookies[1])
+	}
+	if cookies[2].Name != "prefs" || cookies[2].MaxAge != -1 || cookies[2].Path != "/settings" || cookies[2].Domain != "example.com" {
+		t.Errorf("Clear() cookie = %+v, want expired prefs with matching scope", cookies[2])
+	}
+}
+
+func TestJar(t *testing.T) {
+	jar := NewJar(
+		&Config{Name: "session", Path: "/"},
+		&Config{Name: "prefs", Path: "/settings"},
+		&Config{},
+		nil,
+	)
+
+	if _, ok := jar.Config("prefs"); !ok {
+		t.Error("Config() should return registered config")
+	}
+
+	c, rec := newEchoContext(&http.Cookie{Name: "prefs", Value: "dark"})
+
+	if err := jar.Set(c, "session", "abc"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := jar.Set(c, "unknown", "abc"); !errors.Is(err, ErrUnknownName) {
+		t.Errorf("Set() unknown error = %v, want %v", err, ErrUnknownName)
+	}
+
+	if got, err := jar.Get(c, "prefs"); err != nil || got != "dark" {
+		t.Errorf("Get() = %q, %v; want %q, nil", got, err, "dark")
+	}
+	if _, err := jar.Get(c, "unknown"); !errors.Is(err, ErrUnknownName) {
+		t.Errorf("Get() unknown error = %v, want %v", err, ErrUnknownName)
+	}
+	if _, err := jar.Get(c, "session"); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("Get() missing error = %v, want %v", err, http.ErrNoCookie)
+	}
+
+	jar.Clear(c)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 3 {
+		t.Fatalf("got %d cookies, want 3", len(cookies))
+	}
+	if cookies[1].Name != "session" || cookies[1].MaxAge != -1 {
+		t.Errorf("Clear() cookie = %+v, want expired session", cookies[1])
+	}
+	if cookies[2].Name != "prefs" || cookies[2].MaxAge != -1 || cookies[2].Path != "/settings" {
+		t.Errorf("Clear() cookie = %+v, want expired prefs", cookies[2])
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HostPrefix is the cookie name prefix that requires Secure, Path=/ and no Domain.
+	HostPrefix = "__Host-"
+
+	// SecurePrefix is the cookie name prefix that requires Secure.
+	SecurePrefix = "__Secure-"
+)
+
+var (
+	// ErrInvalidName indicates that a cookie name contains characters not allowed by RFC 6265.
+	ErrInvalidName = errors.New("cookie: invalid name")
+
+	// ErrInvalidValue indicates that a cookie value contains characters not allowed by RFC 6265.
+	ErrInvalidValue = errors.New("cookie: invalid value")
+
+	// ErrPrefixRequiresSecure indicates a __Host- or __Secure- cookie without Secure.
+	ErrPrefixRequiresSecure = errors.New("cookie: prefixed cookie requires Secure")
+
+	// ErrHostPrefixDomain indicates a __Host- cookie with a Domain attribute.
+	ErrHostPrefixDomain = errors.New("cookie: __Host- cookie must not set Domain")
+
+	// ErrHostPrefixPath indicates a __Host- cookie with a Path other than "/".
+	ErrHostPrefixPath = errors.New(`cookie: __Host- cookie requires Path "/"`)
+
+	// ErrSameSiteNoneRequiresSecure indicates SameSite=None without Secure.
+	ErrSameSiteNoneRequiresSecure = errors.New("cookie: SameSite=None requires Secure")
+)
+
+// SecureDefaults returns a Config with the recommended security settings for
+// the given cookie name: Path "/", Secure, HTTPOnly and SameSite=Lax.
+//
+// The returned config satisfies the requirements of both the __Host- and
+// __Secure- prefixes, so it can be used with prefixed names as is.
+//
+// Example:
+//
+//	config := cookie.SecureDefaults(cookie.HostPrefix + "session")
+//	config.MaxAge = 3600
+//	c, err := cookie.NewValidated("", token, config)
+func SecureDefaults(name string) *Config {
+	return &Config{
+		Name:     name,
+		Path:     "/",
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Validate checks the config for attribute combinations that browsers reject
+// or silently ignore when used for a cookie with the given name.
+//
+// If name is empty, config.Name is used. Validate returns an error if:
+//   - the name is empty or not a valid RFC 6265 token
+//   - the name has the __Secure- or __Host- prefix and Secure is false
+//   - the name has the __Host- prefix and Domain is set or Path is not "/"
+//   - SameSite is None and Secure is false
+//
+// Prefixes are matched case-insensitively, as browsers do.
+func (c *Config) Validate(name string) error {
+	if name == "" {
+		name = c.Name
+	}
+
+	if name == "" {
+		return ErrMissingName
+	}
+
+	if !isToken(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(lower, strings.ToLower(HostPrefix)):
+		if !c.Secure {
+			return fmt.Errorf("%w: %q", ErrPrefixRequiresSecure, name)
+		}
+		if c.Domain != "" {
+			return fmt.Errorf("%w: %q", ErrHostPrefixDomain, name)
+		}
+		if c.Path != "/" {
+			return fmt.Errorf("%w: %q has path %q", ErrHostPrefixPath, name, c.Path)
+		}
+	case strings.HasPrefix(lower, strings.ToLower(SecurePrefix)):
+		if !c.Secure {
+			return fmt.Errorf("%w: %q", ErrPrefixRequiresSecure, name)
+		}
+	}
+
+	if c.SameSite == http.SameSiteNoneMode && !c.Secure {
+		return fmt.Errorf("%w: %q", ErrSameSiteNoneRequiresSecure, name)
+	}
+
+	return nil
+}
+
+// NewValidated returns a new http.Cookie like New, after validating the
+// config with Config.Validate and the value against the RFC 6265
+// cookie-value grammar.
+//
+// Example:
+//
+//	c, err := cookie.NewValidated("__Host-session", token, cookie.SecureDefaults(""))
+//	if err != nil {
+//		return err
+//	}
+//	http.SetCookie(w, c)
+func NewValidated(name, value string, config *Config) (*http.Cookie, error) {
+	if err := config.Validate(name); err != nil {
+		return nil, err
+	}
+
+	if !isCookieValue(value) {
+		return nil, ErrInvalidValue
+	}
+
+	return New(name, value, config), nil
+}
+
+// isToken reports whether s is a valid RFC 2616 token as required for
+// cookie names by RFC 6265 section 4.1.1.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b <= ' ' || b >= 0x7f || strings.IndexByte(`()<>@,;:\"/[]?={}`, b) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isCookieValue reports whether s matches the cookie-value grammar of
+// RFC 6265 section 4.1.1, optionally enclosed in double quotes.
+func isCookieValue(s string) bool {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isCookieOctet(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isCookieOctet reports whether b is a cookie-octet:
+// %x21 / %x23-2B / %x2D-3A / %x3C-5B / %x5D-7E.
+func isCookieOctet(b byte) bool {
+	return b >= 0x21 && b <= 0x7e && b != '"' && b != ',' && b != ';' && b != '\\'
+}
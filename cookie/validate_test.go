@@ -0,0 +1,190 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cookie
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cookName string
+		config   *Config
+		wantErr  error
+	}{
+		{
+			name:     "secure defaults",
+			cookName: "session",
+			config:   SecureDefaults(""),
+		},
+		{
+			name:    "config name fallback",
+			config:  SecureDefaults("session"),
+			wantErr: nil,
+		},
+		{
+			name:    "missing name",
+			config:  &Config{},
+			wantErr: ErrMissingName,
+		},
+		{
+			name:     "name with separator",
+			cookName: "my;cookie",
+			config:   &Config{},
+			wantErr:  ErrInvalidName,
+		},
+		{
+			name:     "name with space",
+			cookName: "my cookie",
+			config:   &Config{},
+			wantErr:  ErrInvalidName,
+		},
+		{
+			name:     "host prefix with secure defaults",
+			cookName: "__Host-session",
+			config:   SecureDefaults(""),
+		},
+		{
+			name:     "host prefix without secure",
+			cookName: "__Host-session",
+			config:   &Config{Path: "/"},
+			wantErr:  ErrPrefixRequiresSecure,
+		},
+		{
+			name:     "host prefix with domain",
+			cookName: "__Host-session",
+			config:   &Config{Path: "/", Secure: true, Domain: "example.com"},
+			wantErr:  ErrHostPrefixDomain,
+		},
+		{
+			name:     "host prefix with non-root path",
+			cookName: "__Host-session",
+			config:   &Config{Path: "/api", Secure: true},
+			wantErr:  ErrHostPrefixPath,
+		},
+		{
+			name:     "host prefix with empty path",
+			cookName: "__Host-session",
+			config:   &Config{Secure: true},
+			wantErr:  ErrHostPrefixPath,
+		},
+		{
+			name:     "host prefix is case-insensitive",
+			cookName: "__HOST-session",
+			config:   &Config{Path: "/", Secure: true, Domain: "example.com"},
+			wantErr:  ErrHostPrefixDomain,
+		},
+		{
+			name:     "secure prefix with domain and path",
+			cookName: "__Secure-token",
+			config:   &Config{Path: "/api", Domain: "example.com", Secure: true},
+		},
+		{
+			name:     "secure prefix without secure",
+			cookName: "__Secure-token",
+			config:   &Config{},
+			wantErr:  ErrPrefixRequiresSecure,
+		},
+		{
+			name:     "samesite none without secure",
+			cookName: "tracking",
+			config:   &Config{SameSite: http.SameSiteNoneMode},
+			wantErr:  ErrSameSiteNoneRequiresSecure,
+		},
+		{
+			name:     "samesite none with secure",
+			cookName: "tracking",
+			config:   &Config{SameSite: http.SameSiteNoneMode, Secure: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate(tt.cookName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewValidated(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		config  *Config
+		wantErr error
+	}{
+		{
+			name:   "valid value",
+			value:  "abc123-_.~",
+			config: SecureDefaults("session"),
+		},
+		{
+			name:   "quoted value",
+			value:  `"abc"`,
+			config: SecureDefaults("session"),
+		},
+		{
+			name:   "empty value",
+			value:  "",
+			config: SecureDefaults("session"),
+		},
+		{
+			name:    "value with semicolon",
+			value:   "a;b",
+			config:  SecureDefaults("session"),
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "value with space",
+			value:   "a b",
+			config:  SecureDefaults("session"),
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "value with non-ascii",
+			value:   "grüße",
+			config:  SecureDefaults("session"),
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "invalid config",
+			value:   "abc",
+			config:  &Config{Name: "__Host-session"},
+			wantErr: ErrPrefixRequiresSecure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewValidated("", tt.value, tt.config)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewValidated() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if got != nil {
+					t.Errorf("NewValidated() = %v, want nil", got)
+				}
+				return
+			}
+
+			if got == nil || got.Value != tt.value || got.Name != tt.config.Name {
+				t.Errorf("NewValidated() = %v, want cookie %q=%q", got, tt.config.Name, tt.value)
+			}
+		})
+	}
+}
+
+func TestSecureDefaults(t *testing.T) {
+	config := SecureDefaults("session")
+
+	if config.Name != "session" || config.Path != "/" || !config.Secure || !config.HTTPOnly || config.SameSite != http.SameSiteLaxMode {
+		t.Errorf("SecureDefaults() = %+v, want secure profile", config)
+	}
+}
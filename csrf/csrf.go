@@ -0,0 +1,364 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kopexa-grc/x/cookie"
+	"github.com/kopexa-grc/x/echolog"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultCookieName is the cookie name used when Config.Cookie.Name is empty.
+	DefaultCookieName = "_csrf"
+
+	// DefaultHeaderName is the request header checked for the token.
+	DefaultHeaderName = "X-CSRF-Token"
+
+	// DefaultFormField is the form field checked for the token.
+	DefaultFormField = "_csrf"
+
+	// DefaultContextKey is the echo.Context key under which the token is stored.
+	DefaultContextKey = "csrf"
+
+	// DefaultTokenLength is the number of random bytes in a token.
+	DefaultTokenLength = 32
+)
+
+var (
+	// ErrTokenMissing indicates that the request carries no CSRF cookie or no submitted token.
+	ErrTokenMissing = errors.New("csrf: token missing")
+
+	// ErrTokenMismatch indicates that the submitted token does not match the cookie.
+	ErrTokenMismatch = errors.New("csrf: token mismatch")
+
+	// ErrOriginMismatch indicates that the Origin or Referer header names an untrusted origin.
+	ErrOriginMismatch = errors.New("csrf: origin not allowed")
+)
+
+// Config defines the configuration for the CSRF middleware.
+type Config struct {
+	// Skipper defines a function to skip the middleware for certain requests.
+	// If nil, middleware.DefaultSkipper is used (never skips).
+	Skipper middleware.Skipper
+
+	// Cookie configures the token cookie. HTTPOnly should be false when
+	// client-side scripts need to read the token for the header.
+	// Cookie.Name defaults to DefaultCookieName, Cookie.Path to "/" and
+	// Cookie.SameSite to http.SameSiteLaxMode.
+	Cookie cookie.Config
+
+	// Codec optionally signs or encrypts the token cookie. When set, cookies
+	// that were not issued by the server (e.g. injected from a sibling
+	// subdomain) are rejected.
+	Codec cookie.Codec
+
+	// HeaderName is the request header carrying the submitted token.
+	// Defaults to DefaultHeaderName.
+	HeaderName string
+
+	// FormField is the form field carrying the submitted token. It is used
+	// when the header is absent.
+	// Defaults to DefaultFormField.
+	FormField string
+
+	// ContextKey is the echo.Context key under which the current token is
+	// stored for use in templates and responses.
+	// Defaults to DefaultContextKey.
+	ContextKey string
+
+	// TokenLength is the number of random bytes in a token.
+	// Defaults to DefaultTokenLength.
+	TokenLength int
+
+	// TrustedOrigins lists additional origins (e.g. "https://app.example.com")
+	// that may submit unsafe requests. The request's own origin is always trusted.
+	TrustedOrigins []string
+
+	// Logger is used to log rejections when the request context carries no
+	// logger from echolog.LoggingMiddleware.
+	// If nil, a default logger writing to os.Stdout with timestamps is created.
+	Logger *echolog.Logger
+
+	// RequestIDHeader is the header read for the request ID when logging
+	// through Logger. Defaults to echo.HeaderXRequestID.
+	RequestIDHeader string
+
+	// ErrorHandler is called when a request is rejected. If nil, a 403
+	// echo.HTTPError wrapping the reason is returned.
+	ErrorHandler func(c echo.Context, err error) error
+}
+
+// Middleware returns a double-submit-cookie CSRF middleware with default configuration.
+func Middleware() echo.MiddlewareFunc {
+	return MiddlewareWithConfig(Config{})
+}
+
+// MiddlewareWithConfig returns a double-submit-cookie CSRF middleware.
+//
+// A random token is issued in a cookie created with cookie.New and made
+// available to handlers under Config.ContextKey. Requests with unsafe methods
+// (everything except GET, HEAD, OPTIONS and TRACE) must submit the same token
+// in the configured header or form field, and their Origin or Referer header,
+// if present, must match the request origin or one of Config.TrustedOrigins.
+//
+// Rejections are logged with the logger from the request context, so that
+// they carry the request ID set by echolog.LoggingMiddleware.
+//
+// Example:
+//
+//	e := echo.New()
+//	e.Use(echolog.LoggingMiddleware(echolog.Config{Logger: logger}))
+//	e.Use(csrf.MiddlewareWithConfig(csrf.Config{
+//		Cookie: cookie.Config{
+//			Name:     "__Host-csrf",
+//			Path:     "/",
+//			Secure:   true,
+//			SameSite: http.SameSiteStrictMode,
+//		},
+//		TrustedOrigins: []string{"https://admin.example.com"},
+//	}))
+func MiddlewareWithConfig(config Config) echo.MiddlewareFunc {
+	config = withDefaults(config)
+
+	trusted := make(map[string]struct{}, len(config.TrustedOrigins))
+	for _, o := range config.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(o, "/"))] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			token, issued := config.readToken(c)
+			if token == "" {
+				var err error
+				if token, err = config.issueToken(c); err != nil {
+					return err
+				}
+			}
+
+			c.Set(config.ContextKey, token)
+			c.SetRequest(c.Request().WithContext(
+				context.WithValue(c.Request().Context(), tokenKey{}, token),
+			))
+
+			if isSafeMethod(c.Request().Method) {
+				return next(c)
+			}
+
+			if err := checkOrigin(c, trusted); err != nil {
+				return config.reject(c, err)
+			}
+
+			if !issued {
+				return config.reject(c, ErrTokenMissing)
+			}
+
+			submitted := c.Request().Header.Get(config.HeaderName)
+			if submitted == "" {
+				submitted = c.FormValue(config.FormField)
+			}
+
+			if submitted == "" {
+				return config.reject(c, ErrTokenMissing)
+			}
+
+			if subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+				return config.reject(c, ErrTokenMismatch)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// tokenKey is the request context key of the current token. Unlike
+// Config.ContextKey it does not depend on the configuration.
+type tokenKey struct{}
+
+// Token returns the CSRF token issued or read by the middleware for the
+// current request, or an empty string. It works regardless of
+// Config.ContextKey.
+func Token(c echo.Context) string {
+	token, _ := c.Request().Context().Value(tokenKey{}).(string)
+	return token
+}
+
+// withDefaults fills in unset configuration values.
+func withDefaults(config Config) Config {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Cookie.Name == "" {
+		config.Cookie.Name = DefaultCookieName
+	}
+
+	if config.Cookie.Path == "" {
+		config.Cookie.Path = "/"
+	}
+
+	if config.Cookie.SameSite == 0 {
+		config.Cookie.SameSite = http.SameSiteLaxMode
+	}
+
+	if config.HeaderName == "" {
+		config.HeaderName = DefaultHeaderName
+	}
+
+	if config.FormField == "" {
+		config.FormField = DefaultFormField
+	}
+
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultContextKey
+	}
+
+	if config.TokenLength <= 0 {
+		config.TokenLength = DefaultTokenLength
+	}
+
+	if config.Logger == nil {
+		config.Logger = echolog.New(os.Stdout, echolog.WithTimestamp())
+	}
+
+	if config.RequestIDHeader == "" {
+		config.RequestIDHeader = echo.HeaderXRequestID
+	}
+
+	return config
+}
+
+// readToken returns the token from the request cookie and whether a valid
+// cookie was present.
+func (config Config) readToken(c echo.Context) (string, bool) {
+	ck, err := c.Cookie(config.Cookie.Name)
+	if err != nil || ck.Value == "" {
+		return "", false
+	}
+
+	if config.Codec != nil {
+		if _, err := config.Codec.Decode(config.Cookie.Name, ck.Value); err != nil {
+			return "", false
+		}
+	}
+
+	return ck.Value, true
+}
+
+// issueToken generates a new token and sets it as cookie on the response.
+func (config Config) issueToken(c echo.Context) (string, error) {
+	b := make([]byte, config.TokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if config.Codec != nil {
+		encoded, err := config.Codec.Encode(config.Cookie.Name, token)
+		if err != nil {
+			return "", err
+		}
+		token = encoded
+	}
+
+	c.SetCookie(cookie.New("", token, &config.Cookie))
+
+	return token, nil
+}
+
+// reject logs the rejection and invokes the error handler.
+func (config Config) reject(c echo.Context, err error) error {
+	req := c.Request()
+
+	logger := echolog.Ctx(req.Context())
+	if logger.GetLevel() == zerolog.Disabled {
+		l := config.Logger.Unwrap()
+		if id := requestID(c, config.RequestIDHeader); id != "" {
+			l = l.With().Str("request_id", id).Logger()
+		}
+		logger = &l
+	}
+
+	logger.Warn().
+		Err(err).
+		Str("method", req.Method).
+		Str("uri", req.RequestURI).
+		Str("origin", req.Header.Get(echo.HeaderOrigin)).
+		Str("remote_ip", c.RealIP()).
+		Msg("csrf validation failed")
+
+	if config.ErrorHandler != nil {
+		return config.ErrorHandler(c, err)
+	}
+
+	return echo.NewHTTPError(http.StatusForbidden, "invalid csrf token").SetInternal(err)
+}
+
+// requestID extracts the request ID from the request or response headers.
+func requestID(c echo.Context, header string) string {
+	id := c.Request().Header.Get(header)
+	if id == "" {
+		id = c.Response().Header().Get(header)
+	}
+
+	return id
+}
+
+// checkOrigin verifies the Origin header, falling back to Referer. Requests
+// carrying neither header are left to the token check.
+func checkOrigin(c echo.Context, trusted map[string]struct{}) error {
+	req := c.Request()
+
+	source := req.Header.Get(echo.HeaderOrigin)
+	if source == "" {
+		source = req.Referer()
+	}
+
+	if source == "" {
+		return nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ErrOriginMismatch
+	}
+
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	if origin == strings.ToLower(c.Scheme()+"://"+req.Host) {
+		return nil
+	}
+
+	if _, ok := trusted[origin]; ok {
+		return nil
+	}
+
+	return ErrOriginMismatch
+}
+
+// isSafeMethod reports whether method is safe as defined by RFC 9110.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package csrf_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kopexa-grc/x/cookie"
+	"github.com/kopexa-grc/x/csrf"
+	"github.com/kopexa-grc/x/echolog"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, config csrf.Config, middlewares ...echo.MiddlewareFunc) *echo.Echo {
+	t.Helper()
+
+	e := echo.New()
+	e.Use(middlewares...)
+	e.Use(csrf.MiddlewareWithConfig(config))
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, csrf.Token(c))
+	}
+	e.GET("/", handler)
+	e.POST("/", handler)
+
+	return e
+}
+
+// issue performs a GET request and returns the issued CSRF cookie.
+func issue(t *testing.T, e *echo.Echo) *http.Cookie {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, rec.Body.String(), cookies[0].Value, "token should be exposed to handlers")
+
+	return cookies[0]
+}
+
+func TestMiddleware_SafeMethods(t *testing.T) {
+	e := newServer(t, csrf.Config{Logger: echolog.New(&bytes.Buffer{})})
+
+	ck := issue(t, e)
+	assert.Equal(t, csrf.DefaultCookieName, ck.Name)
+	assert.Equal(t, "/", ck.Path)
+	assert.Equal(t, http.SameSiteLaxMode, ck.SameSite)
+
+	// existing cookies are reused
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(ck)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+	assert.Equal(t, ck.Value, rec.Body.String())
+}
+
+func TestMiddleware_ContextKey(t *testing.T) {
+	e := echo.New()
+	e.Use(csrf.MiddlewareWithConfig(csrf.Config{ContextKey: "token"}))
+	e.GET("/", func(c echo.Context) error {
+		assert.Equal(t, csrf.Token(c), c.Get("token"))
+		return c.String(http.StatusOK, csrf.Token(c))
+	})
+
+	issue(t, e)
+}
+
+func TestMiddleware_UnsafeMethods(t *testing.T) {
+	e := newServer(t, csrf.Config{
+		Logger:         echolog.New(&bytes.Buffer{}),
+		TrustedOrigins: []string{"https://admin.example.com"},
+	})
+	ck := issue(t, e)
+
+	tests := []struct {
+		name       string
+		cookie     *http.Cookie
+		header     string
+		form       string
+		origin     string
+		referer    string
+		wantStatus int
+	}{
+		{name: "valid header", cookie: ck, header: ck.Value, wantStatus: http.StatusOK},
+		{name: "valid form field", cookie: ck, form: ck.Value, wantStatus: http.StatusOK},
+		{name: "same origin", cookie: ck, header: ck.Value, origin: "http://example.com", wantStatus: http.StatusOK},
+		{name: "trusted origin", cookie: ck, header: ck.Value, origin: "https://admin.example.com", wantStatus: http.StatusOK},
+		{name: "same origin referer", cookie: ck, header: ck.Value, referer: "http://example.com/form", wantStatus: http.StatusOK},
+		{name: "missing cookie", header: ck.Value, wantStatus: http.StatusForbidden},
+		{name: "missing token", cookie: ck, wantStatus: http.StatusForbidden},
+		{name: "wrong token", cookie: ck, header: "forged", wantStatus: http.StatusForbidden},
+		{name: "foreign origin", cookie: ck, header: ck.Value, origin: "https://evil.com", wantStatus: http.StatusForbidden},
+		{name: "null origin", cookie: ck, header: ck.Value, origin: "null", wantStatus: http.StatusForbidden},
+		{name: "foreign referer", cookie: ck, header: ck.Value, referer: "https://evil.com/page", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body *strings.Reader
+			if tt.form != "" {
+				body = strings.NewReader(url.Values{csrf.DefaultFormField: {tt.form}}.Encode())
+			} else {
+				body = strings.NewReader("")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			if tt.form != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			}
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			if tt.header != "" {
+				req.Header.Set(csrf.DefaultHeaderName, tt.header)
+			}
+			if tt.origin != "" {
+				req.Header.Set(echo.HeaderOrigin, tt.origin)
+			}
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestMiddleware_Codec(t *testing.T) {
+	codec, err := cookie.NewSignedCodec([][]byte{bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+
+	e := newServer(t, csrf.Config{Codec: codec, Logger: echolog.New(&bytes.Buffer{})})
+	ck := issue(t, e)
+
+	_, err = codec.Decode(csrf.DefaultCookieName, ck.Value)
+	require.NoError(t, err, "cookie should be signed")
+
+	post := func(value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: csrf.DefaultCookieName, Value: value})
+		req.Header.Set(csrf.DefaultHeaderName, value)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, post(ck.Value))
+	assert.Equal(t, http.StatusForbidden, post("injected"), "unsigned cookies should be rejected")
+}
+
+func TestMiddleware_Skipper(t *testing.T) {
+	e := newServer(t, csrf.Config{
+		Skipper: func(c echo.Context) bool { return c.Request().Header.Get("Authorization") != "" },
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestMiddleware_ErrorHandler(t *testing.T) {
+	var got error
+	e := newServer(t, csrf.Config{
+		Logger: echolog.New(&bytes.Buffer{}),
+		ErrorHandler: func(c echo.Context, err error) error {
+			got = err
+			return c.NoContent(http.StatusTeapot)
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.True(t, errors.Is(got, csrf.ErrTokenMissing))
+}
+
+func TestMiddleware_LogsRequestID(t *testing.T) {
+	t.Run("with echolog middleware", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		e := newServer(t, csrf.Config{}, echolog.LoggingMiddleware(echolog.Config{
+			Logger: echolog.New(buf),
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-123")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Contains(t, buf.String(), `"request_id":"req-123"`)
+		assert.Contains(t, buf.String(), `"message":"csrf validation failed"`)
+		assert.Contains(t, buf.String(), csrf.ErrTokenMissing.Error())
+	})
+
+	t.Run("with fallback logger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		e := newServer(t, csrf.Config{Logger: echolog.New(buf)})
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-456")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Contains(t, buf.String(), `"request_id":"req-456"`)
+		assert.Contains(t, buf.String(), `"message":"csrf validation failed"`)
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package csrf provides double-submit-cookie CSRF protection for Echo applications.
+//
+// # Overview
+//
+// The middleware issues a random token in a cookie built with cookie.New from
+// a cookie.Config. Requests with unsafe methods must echo the token back in a
+// header or form field; a cross-site attacker can trigger requests that carry
+// the cookie but cannot read it to submit the matching token. In addition, the
+// Origin (or Referer) header of unsafe requests must match the request origin
+// or a trusted origin.
+//
+// # Usage
+//
+//	e := echo.New()
+//	e.Use(echolog.LoggingMiddleware(echolog.Config{Logger: logger}))
+//	e.Use(csrf.MiddlewareWithConfig(csrf.Config{
+//		Cookie: cookie.Config{
+//			Name:     "__Host-csrf",
+//			Path:     "/",
+//			Secure:   true,
+//			SameSite: http.SameSiteStrictMode,
+//		},
+//	}))
+//
+//	e.GET("/form", func(c echo.Context) error {
+//		return c.Render(http.StatusOK, "form", map[string]any{
+//			"csrf": csrf.Token(c),
+//		})
+//	})
+//
+// Single-page applications read the cookie (leave HTTPOnly unset) and send
+// the token in the X-CSRF-Token header.
+//
+// # Logging
+//
+// Rejections are logged at warn level through echolog. When the request was
+// processed by echolog.LoggingMiddleware, the request-scoped logger is used
+// and the entry carries its request ID; otherwise Config.Logger is used with
+// the request ID read from the X-Request-ID header.
+package csrf
@@ -4,6 +4,7 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/kopexa-grc/x/cli/theme/colors"
+	"github.com/kopexa-grc/x/multierr"
 	"github.com/muesli/termenv"
 
 	"github.com/rs/zerolog"
@@ -83,7 +85,57 @@ func consoleDefaultFormatErrFieldName() zerolog.Formatter {
 
 func consoleDefaultFormatErrFieldValue() zerolog.Formatter {
 	return func(i interface{}) string {
-		return termenv.String(fmt.Sprintf("%s", i)).Foreground(colors.DefaultColorTheme.Error).String()
+		return termenv.String(formatErrTree(i)).Foreground(colors.DefaultColorTheme.Error).String()
+	}
+}
+
+// formatErrTree renders errors logged as objects, such as multierr.Errors,
+// as an indented tree of their messages:
+//
+//	2 errors occurred
+//	  ├─ scan AC-1: timeout
+//	  └─ scan AC-2: 2 errors occurred
+//	     ├─ not_found: control not found
+//	     └─ access denied
+//
+// Other values are formatted as is.
+func formatErrTree(i interface{}) string {
+	raw, ok := i.([]byte)
+	if !ok {
+		return fmt.Sprintf("%s", i)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return string(raw)
+	}
+	if _, ok := obj[multierr.LogFieldMessage].(string); !ok {
+		return string(raw)
+	}
+
+	var b strings.Builder
+	writeErrTree(&b, obj, "  ")
+	return b.String()
+}
+
+func writeErrTree(b *strings.Builder, obj map[string]interface{}, prefix string) {
+	msg, _ := obj[multierr.LogFieldMessage].(string)
+	b.WriteString(msg)
+
+	children, _ := obj[multierr.LogFieldErrors].([]interface{})
+	for n, c := range children {
+		child, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		branch, indent := "├─ ", "│  "
+		if n == len(children)-1 {
+			branch, indent = "└─ ", "   "
+		}
+
+		b.WriteString("\n" + prefix + branch)
+		writeErrTree(b, child, prefix+indent)
 	}
 }
 
@@ -0,0 +1,42 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kopexa-grc/x/multierr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleWriterErrorTree(t *testing.T) {
+	var nested multierr.Errors
+	nested.Add(multierr.New(multierr.CodeNotFound, "control not found"), errors.New("access denied"))
+
+	var errs multierr.Errors
+	errs.Add(errors.New("scan AC-1: timeout"), multierr.Wrap(&nested, "scan AC-2"))
+
+	t.Run("renders multierr.Errors as a tree", func(t *testing.T) {
+		out := &strings.Builder{}
+		log := NewConsoleWriter(out, true)
+		log.Error().Err(&errs).Msg("scan failed")
+
+		assert.Contains(t, out.String(), "2 errors occurred\n"+
+			"  ├─ scan AC-1: timeout\n"+
+			"  └─ scan AC-2: 2 errors occurred\n"+
+			"     ├─ not_found: control not found\n"+
+			"     └─ access denied")
+	})
+
+	t.Run("keeps plain errors on one line", func(t *testing.T) {
+		out := &strings.Builder{}
+		log := NewConsoleWriter(out, true)
+		log.Error().Err(errors.New("boom")).Msg("failed")
+
+		assert.Contains(t, out.String(), "boom")
+		assert.NotContains(t, out.String(), "├─")
+	})
+}
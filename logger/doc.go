@@ -31,6 +31,17 @@
 //	log := logger.FromContext(ctx)
 //	log.Info().Msg("Processing request") // Includes request ID
 //
+// # Error Logging
+//
+// Errors from the multierr package are logged as objects, with each
+// collected error as an array element. The CLI console writers render them
+// as an indented tree:
+//
+//	log.Error().Err(&errs).Msg("scan failed")
+//	// x scan failed error=2 errors occurred
+//	//   ├─ scan AC-1: timeout
+//	//   └─ not_found: control not found
+//
 // # Performance Tracing
 //
 // Track function execution times:
@@ -0,0 +1,361 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+)
+
+// Code is a machine-readable error code, stable across releases and safe to
+// return to API clients.
+type Code string
+
+// Codes known to this package. Each maps to a default HTTP status, see
+// Code.HTTPStatus. Other codes may be defined by callers; they map to
+// http.StatusInternalServerError unless an explicit status is set.
+const (
+	CodeUnknown            Code = "unknown"
+	CodeInvalidArgument    Code = "invalid_argument"
+	CodeUnauthenticated    Code = "unauthenticated"
+	CodePermissionDenied   Code = "permission_denied"
+	CodeNotFound           Code = "not_found"
+	CodeAlreadyExists      Code = "already_exists"
+	CodeFailedPrecondition Code = "failed_precondition"
+	CodeResourceExhausted  Code = "resource_exhausted"
+	CodeCanceled           Code = "canceled"
+	CodeDeadlineExceeded   Code = "deadline_exceeded"
+	CodeUnavailable        Code = "unavailable"
+	CodeInternal           Code = "internal"
+)
+
+var codeStatus = map[Code]int{
+	CodeInvalidArgument:    http.StatusBadRequest,
+	CodeUnauthenticated:    http.StatusUnauthorized,
+	CodePermissionDenied:   http.StatusForbidden,
+	CodeNotFound:           http.StatusNotFound,
+	CodeAlreadyExists:      http.StatusConflict,
+	CodeFailedPrecondition: http.StatusPreconditionFailed,
+	CodeResourceExhausted:  http.StatusTooManyRequests,
+	CodeCanceled:           499, // client closed request
+	CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+}
+
+// HTTPStatus returns the default HTTP status for the code.
+//
+// Unknown and custom codes map to http.StatusInternalServerError.
+func (c Code) HTTPStatus() int {
+	if status, ok := codeStatus[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error carrying structured information for API responses: a
+// machine-readable code, an HTTP status, a message that is safe to show to
+// users, details and whether the operation may be retried.
+//
+// An Error may wrap an internal cause. The cause is part of Error() for logs
+// but never part of the user-safe message, and it is reachable with
+// errors.Is and errors.As. Errors created with New and WithCode can be
+// wrapped further with Wrap or fmt.Errorf("...: %w", err); use errors.As or
+// the helpers CodeOf, HTTPStatus, IsRetryable and ToResponse to retrieve
+// them.
+type Error struct {
+	// Code is the machine-readable error code.
+	Code Code
+
+	// Status is the HTTP status. If zero, Code.HTTPStatus is used.
+	Status int
+
+	// Message is a user-safe description of the error.
+	Message string
+
+	// Details holds additional user-safe information, such as the name of
+	// an invalid field. It must be serializable as JSON.
+	Details map[string]any
+
+	// Retryable reports whether the operation may succeed if retried.
+	Retryable bool
+
+	cause error
+}
+
+// ErrorOption configures an Error created by New or WithCode.
+type ErrorOption func(*Error)
+
+// WithStatus sets the HTTP status, overriding the default of the code.
+func WithStatus(status int) ErrorOption {
+	return func(e *Error) {
+		e.Status = status
+	}
+}
+
+// WithDetail adds a detail to the error.
+func WithDetail(key string, value any) ErrorOption {
+	return func(e *Error) {
+		if e.Details == nil {
+			e.Details = map[string]any{}
+		}
+		e.Details[key] = value
+	}
+}
+
+// WithDetails adds all entries of details to the error.
+func WithDetails(details map[string]any) ErrorOption {
+	return func(e *Error) {
+		if e.Details == nil {
+			e.Details = make(map[string]any, len(details))
+		}
+		maps.Copy(e.Details, details)
+	}
+}
+
+// WithRetryable marks the error as retryable or not.
+func WithRetryable(retryable bool) ErrorOption {
+	return func(e *Error) {
+		e.Retryable = retryable
+	}
+}
+
+// New returns an Error with the code and user-safe message.
+//
+// Example:
+//
+//	var ErrControlNotFound = multierr.New(multierr.CodeNotFound, "control not found")
+//
+//	return multierr.New(multierr.CodeInvalidArgument, "name is required",
+//	    multierr.WithDetail("field", "name"),
+//	)
+func New(code Code, message string, opts ...ErrorOption) *Error {
+	e := &Error{Code: code, Message: message}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithCode wraps err in an Error with the code and user-safe message.
+//
+// If err is nil, WithCode returns nil, like Wrap.
+//
+// Example:
+//
+//	row, err := db.GetControl(ctx, id)
+//	if errors.Is(err, sql.ErrNoRows) {
+//	    return multierr.WithCode(err, multierr.CodeNotFound, "control not found",
+//	        multierr.WithDetail("id", id))
+//	}
+func WithCode(err error, code Code, message string, opts ...ErrorOption) error {
+	if err == nil {
+		return nil
+	}
+
+	e := New(code, message, opts...)
+	e.cause = err
+	return e
+}
+
+// Error returns the code, message and cause of the error for logs.
+//
+// The format is: "code: message: cause"
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped cause, or nil.
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is reports whether target is an *Error without cause and with the same
+// code and message, so that errors created with New can be used as
+// sentinels:
+//
+//	errors.Is(err, ErrControlNotFound) // not_found errors with the same message
+//
+// Sentinels sharing a code do not match each other; use HasCode to match
+// any error with a code.
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *Error:
+		return t.cause == nil && t.Code == e.Code && t.Message == e.Message
+	case codeTarget:
+		return Code(t) == e.Code
+	}
+	return false
+}
+
+// codeTarget matches any *Error with the code, see HasCode.
+type codeTarget Code
+
+func (c codeTarget) Error() string { return string(c) }
+
+// HasCode reports whether any Error in the chain of err has the code,
+// including the errors of an *Errors collection.
+//
+//	multierr.HasCode(err, multierr.CodeNotFound) // any not_found error
+func HasCode(err error, code Code) bool {
+	return errors.Is(err, codeTarget(code))
+}
+
+// HTTPStatus returns Status, or the default status of Code if unset.
+func (e *Error) HTTPStatus() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	return e.Code.HTTPStatus()
+}
+
+// CodeOf returns the code of the first Error in the chain of err.
+//
+// It returns the empty code for nil and CodeUnknown for errors without code.
+// For an *Errors collection, it returns the code shared by all collected
+// errors, or CodeUnknown if they differ.
+func CodeOf(err error) Code {
+	var r responder
+	switch {
+	case err == nil:
+		return ""
+	case !errors.As(err, &r):
+		return CodeUnknown
+	}
+	return r.response().Code
+}
+
+// HTTPStatus returns the HTTP status for err: http.StatusOK for nil, the
+// status of the first Error in the chain, or http.StatusInternalServerError
+// for errors without code.
+//
+// For an *Errors collection, it returns the status shared by all collected
+// errors. If they differ, it returns http.StatusBadRequest when all are
+// client errors and http.StatusInternalServerError otherwise.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	return ToResponse(err).Status
+}
+
+// IsRetryable reports whether the first Error in the chain of err is
+// retryable. For an *Errors collection, all collected errors must be
+// retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	return ToResponse(err).Retryable
+}
+
+// Response is the user-safe representation of an error for API responses.
+type Response struct {
+	// Status is the HTTP status; it is not part of the JSON body.
+	Status int `json:"-"`
+
+	Code      Code           `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	Retryable bool           `json:"retryable,omitempty"`
+
+	// Errors lists the individual errors of an *Errors collection.
+	Errors []Response `json:"errors,omitempty"`
+}
+
+// internalMessage replaces the message of errors without code, which may
+// contain internal information.
+const internalMessage = "internal error"
+
+// ToResponse converts err into a user-safe Response.
+//
+// Errors with an Error in their chain use its code, status, message, details
+// and retryable flag. Other errors become CodeInternal with a generic
+// message, so that internal messages never reach clients. An *Errors
+// collection lists every collected error in Response.Errors.
+//
+// Example with Echo:
+//
+//	e := echo.New()
+//	e.HTTPErrorHandler = func(err error, c echo.Context) {
+//	    if c.Response().Committed {
+//	        return
+//	    }
+//	    resp := multierr.ToResponse(err)
+//	    _ = c.JSON(resp.Status, resp)
+//	}
+func ToResponse(err error) Response {
+	var r responder
+	if !errors.As(err, &r) {
+		return Response{
+			Status:  http.StatusInternalServerError,
+			Code:    CodeInternal,
+			Message: internalMessage,
+		}
+	}
+	return r.response()
+}
+
+// responder is implemented by *Error and *Errors, so that ToResponse uses
+// whichever comes first in the chain.
+type responder interface {
+	error
+	response() Response
+}
+
+func (e *Error) response() Response {
+	return Response{
+		Status:    e.HTTPStatus(),
+		Code:      e.Code,
+		Message:   e.Message,
+		Details:   maps.Clone(e.Details),
+		Retryable: e.Retryable,
+	}
+}
+
+// response aggregates the responses of the collected errors.
+func (m *Errors) response() Response {
+	if m.IsEmpty() {
+		return Response{Status: http.StatusInternalServerError, Code: CodeInternal, Message: internalMessage}
+	}
+
+	resp := Response{
+		Retryable: true,
+		Errors:    make([]Response, len(m.Errors)),
+	}
+
+	resp.Message = m.summary()
+
+	clientErrors := true
+	for i, err := range m.Errors {
+		r := ToResponse(err)
+		resp.Errors[i] = r
+
+		if i == 0 {
+			resp.Status, resp.Code = r.Status, r.Code
+		}
+		if r.Status != resp.Status {
+			resp.Status = 0
+		}
+		if r.Code != resp.Code {
+			resp.Code = CodeUnknown
+		}
+		clientErrors = clientErrors && r.Status >= 400 && r.Status < 500
+		resp.Retryable = resp.Retryable && r.Retryable
+	}
+
+	if resp.Status == 0 {
+		resp.Status = http.StatusInternalServerError
+		if clientErrors {
+			resp.Status = http.StatusBadRequest
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kopexa-grc/x/multierr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errNotFound = multierr.New(multierr.CodeNotFound, "control not found")
+	errDatabase = errors.New("sql: no rows in result set")
+)
+
+func TestError(t *testing.T) {
+	err := multierr.WithCode(errDatabase, multierr.CodeNotFound, "control not found",
+		multierr.WithDetail("id", "AC-1"),
+		multierr.WithRetryable(true),
+	)
+	assert.Equal(t, "not_found: control not found: sql: no rows in result set", err.Error())
+
+	wrapped := multierr.Wrap(fmt.Errorf("load: %w", err), "handle request")
+	assert.ErrorIs(t, wrapped, errDatabase)
+	assert.ErrorIs(t, wrapped, errNotFound)
+	assert.NotErrorIs(t, wrapped, multierr.New(multierr.CodeInternal, ""))
+	assert.True(t, multierr.HasCode(wrapped, multierr.CodeNotFound))
+	assert.False(t, multierr.HasCode(wrapped, multierr.CodeInternal))
+
+	var coded *multierr.Error
+	require.ErrorAs(t, wrapped, &coded)
+	assert.Equal(t, multierr.CodeNotFound, coded.Code)
+	assert.Equal(t, map[string]any{"id": "AC-1"}, coded.Details)
+	assert.Equal(t, http.StatusNotFound, coded.HTTPStatus())
+
+	assert.Equal(t, multierr.CodeNotFound, multierr.CodeOf(wrapped))
+	assert.Equal(t, http.StatusNotFound, multierr.HTTPStatus(wrapped))
+	assert.True(t, multierr.IsRetryable(wrapped))
+
+	assert.NoError(t, multierr.WithCode(nil, multierr.CodeInternal, "ignored"))
+}
+
+func TestError_IsSentinel(t *testing.T) {
+	errUserNotFound := multierr.New(multierr.CodeNotFound, "user not found")
+	errOrgNotFound := multierr.New(multierr.CodeNotFound, "organization not found")
+
+	err := multierr.Wrap(errUserNotFound, "load user")
+	assert.ErrorIs(t, err, errUserNotFound)
+	assert.NotErrorIs(t, err, errOrgNotFound)
+	assert.NotErrorIs(t, errOrgNotFound, errUserNotFound)
+	assert.True(t, multierr.HasCode(err, multierr.CodeNotFound))
+
+	var errs multierr.Errors
+	errs.Add(errDatabase, errOrgNotFound)
+	assert.True(t, multierr.HasCode(&errs, multierr.CodeNotFound))
+	assert.False(t, multierr.HasCode(errDatabase, multierr.CodeNotFound))
+	assert.False(t, multierr.HasCode(nil, multierr.CodeNotFound))
+}
+
+func TestError_Options(t *testing.T) {
+	err := multierr.New(multierr.Code("quota_exceeded"), "quota exceeded",
+		multierr.WithStatus(http.StatusPaymentRequired),
+		multierr.WithDetails(map[string]any{"limit": 10, "used": 10}),
+		multierr.WithDetail("plan", "free"),
+	)
+	assert.Equal(t, "quota_exceeded: quota exceeded", err.Error())
+	assert.Equal(t, http.StatusPaymentRequired, err.HTTPStatus())
+	assert.Equal(t, map[string]any{"limit": 10, "used": 10, "plan": "free"}, err.Details)
+
+	assert.Equal(t, http.StatusInternalServerError, multierr.Code("quota_exceeded").HTTPStatus())
+	assert.Equal(t, http.StatusConflict, multierr.CodeAlreadyExists.HTTPStatus())
+}
+
+func TestHelpers_UncodedErrors(t *testing.T) {
+	assert.Equal(t, multierr.Code(""), multierr.CodeOf(nil))
+	assert.Equal(t, http.StatusOK, multierr.HTTPStatus(nil))
+	assert.False(t, multierr.IsRetryable(nil))
+
+	assert.Equal(t, multierr.CodeUnknown, multierr.CodeOf(errDatabase))
+	assert.Equal(t, http.StatusInternalServerError, multierr.HTTPStatus(errDatabase))
+	assert.False(t, multierr.IsRetryable(errDatabase))
+}
+
+func TestToResponse(t *testing.T) {
+	resp := multierr.ToResponse(multierr.Wrap(errDatabase, "query"))
+	assert.Equal(t, multierr.Response{
+		Status:  http.StatusInternalServerError,
+		Code:    multierr.CodeInternal,
+		Message: "internal error",
+	}, resp, "internal messages are not exposed")
+
+	err := multierr.WithCode(errDatabase, multierr.CodeUnavailable, "try again later",
+		multierr.WithRetryable(true))
+	data, jsonErr := json.Marshal(multierr.ToResponse(err))
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"code": "unavailable", "message": "try again later", "retryable": true}`, string(data))
+}
+
+func TestToResponse_Errors(t *testing.T) {
+	invalid := func(field string) error {
+		return multierr.New(multierr.CodeInvalidArgument, field+" is required", multierr.WithDetail("field", field))
+	}
+
+	tests := []struct {
+		name   string
+		errs   []error
+		status int
+		code   multierr.Code
+	}{
+		{
+			name:   "same code",
+			errs:   []error{invalid("name"), invalid("owner")},
+			status: http.StatusBadRequest,
+			code:   multierr.CodeInvalidArgument,
+		},
+		{
+			name:   "client errors",
+			errs:   []error{invalid("name"), errNotFound},
+			status: http.StatusBadRequest,
+			code:   multierr.CodeUnknown,
+		},
+		{
+			name:   "server error",
+			errs:   []error{invalid("name"), errDatabase},
+			status: http.StatusInternalServerError,
+			code:   multierr.CodeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs multierr.Errors
+			errs.Add(tt.errs...)
+
+			resp := multierr.ToResponse(multierr.Wrap(&errs, "validate"))
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Equal(t, tt.code, resp.Code)
+			assert.Equal(t, "2 errors occurred", resp.Message)
+			assert.False(t, resp.Retryable)
+			require.Len(t, resp.Errors, 2)
+			assert.Equal(t, map[string]any{"field": "name"}, resp.Errors[0].Details)
+		})
+	}
+
+	// a code wrapping a collection takes precedence
+	var errs multierr.Errors
+	errs.Add(invalid("name"))
+	err := multierr.WithCode(&errs, multierr.CodeFailedPrecondition, "control is locked")
+	assert.Equal(t, multierr.CodeFailedPrecondition, multierr.CodeOf(err))
+	assert.Empty(t, multierr.ToResponse(err).Errors)
+}
@@ -15,6 +15,12 @@
 //   - Flexible error filtering with custom predicates
 //   - Comprehensive error formatting for debugging
 //   - Error wrapping functionality compatible with Go 1.13+ error handling
+//   - Optional stack trace capture in Wrap, interoperable with
+//     github.com/cockroachdb/errors
+//   - Concurrent error groups collecting every failure and recovered panics
+//   - Structured logging of collections and wrapped errors with zerolog
+//   - Structured errors with codes, HTTP statuses and user-safe messages
+//     for API responses
 //
 // # Basic Usage
 //
@@ -46,6 +52,20 @@
 //	    return multierr.Wrap(err, "failed to process user data")
 //	}
 //
+// Capturing and printing stack traces:
+//
+//	err := multierr.Wrap(err, "failed to load config", multierr.WithStack())
+//	log.Printf("%+v", err) // the chain with the stack trace of the Wrap call
+//
+// Structured errors for API responses:
+//
+//	if errors.Is(err, sql.ErrNoRows) {
+//	    return multierr.WithCode(err, multierr.CodeNotFound, "control not found")
+//	}
+//
+//	resp := multierr.ToResponse(err)
+//	return c.JSON(resp.Status, resp)
+//
 // # Performance
 //
 // The package is designed for efficiency with minimal allocations during error collection.
@@ -58,6 +78,16 @@
 // unwrapping and the errors.Is/errors.As functions. The Wrap function creates errors
 // that properly implement the Unwrap() method.
 //
+// Errors implements the Go 1.20 Unwrap() []error method, so errors.Is and errors.As
+// inspect every collected error, as they do for errors.Join:
+//
+//	var errs multierr.Errors
+//	errs.Add(validate(user), store(user))
+//
+//	if errors.Is(errs.ErrorOrNil(), context.DeadlineExceeded) {
+//	    // at least one operation timed out
+//	}
+//
 // For more information about Kopexa's Go ecosystem, visit https://kopexa.com
 package multierr
 
@@ -117,9 +147,15 @@ func (w withMessage) Unwrap() error { return w.cause }
 // If the provided error is nil, Wrap returns nil, making it safe to use
 // in conditional chains without additional nil checks.
 //
+// With WithStack, or by default after SetStackCapture(true), Wrap captures
+// the stack trace of its caller. The stack trace is printed with %+v and
+// returned by StackFrames, and github.com/cockroachdb/errors prints and
+// reports it like its own.
+//
 // Parameters:
 //   - err: the error to wrap (can be nil)
 //   - message: contextual message to add to the error
+//   - opts: WithStack, WithoutStack and WithCallerSkip
 //
 // Returns:
 //   - error: wrapped error with additional context, or nil if err was nil
@@ -150,14 +186,24 @@ func (w withMessage) Unwrap() error { return w.cause }
 //	        log.Printf("Processing failed: %v", err)
 //	    }
 //	}
-func Wrap(err error, message string) error {
+func Wrap(err error, message string, opts ...WrapOption) error {
 	if err == nil {
 		return nil
 	}
-	return withMessage{
+
+	o := wrapOptions{stack: captureStacks.Load()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := withMessage{
 		cause: err,
 		msg:   message,
 	}
+	if !o.stack {
+		return w
+	}
+	return withStack{withMessage: w, stack: callers(o.skip + 1)}
 }
 
 // Errors represents a collection of errors that can be accumulated and processed together.
@@ -183,7 +229,7 @@ func Wrap(err error, message string) error {
 //	}
 //
 // Thread safety: Errors is not safe for concurrent use. If you need to collect
-// errors from multiple goroutines, use appropriate synchronization mechanisms.
+// errors from multiple goroutines, use a Group or appropriate synchronization mechanisms.
 type Errors struct {
 	// Errors contains the collected error instances.
 	// This field is exported to allow direct access when needed, but it's
@@ -231,7 +277,8 @@ func (m *Errors) Add(err ...error) {
 // a new Errors instance containing only the errors for which the predicate returns false.
 // This is useful for removing specific types of errors or errors matching certain criteria.
 //
-// The original Errors collection is not modified.
+// The original Errors collection is not modified, and the order of the remaining
+// errors is preserved. Filter is safe to call on a nil receiver.
 //
 // Parameters:
 //   - f: predicate function that returns true for errors to be excluded
@@ -266,6 +313,9 @@ func (m *Errors) Add(err ...error) {
 //	})
 func (m *Errors) Filter(f func(e error) bool) *Errors {
 	res := Errors{}
+	if m == nil {
+		return &res
+	}
 	for i := range m.Errors {
 		cur := m.Errors[i]
 		if !f(cur) {
@@ -310,16 +360,21 @@ func (m *Errors) Filter(f func(e error) bool) *Errors {
 func (m *Errors) Error() string {
 	var res strings.Builder
 
-	n := strconv.Itoa(len(m.Errors))
+	var errs []error
+	if m != nil {
+		errs = m.Errors
+	}
+
+	n := strconv.Itoa(len(errs))
 	if n == "1" {
 		res.WriteString("1 error occurred:\n")
 	} else {
 		res.WriteString(n + " errors occurred:\n")
 	}
 
-	for i := range m.Errors {
+	for i := range errs {
 		res.WriteString("\t* ")
-		res.WriteString(m.Errors[i].Error())
+		res.WriteString(errs[i].Error())
 		res.WriteByte('\n')
 	}
 	return res.String()
@@ -333,7 +388,9 @@ func (m *Errors) Error() string {
 // If multiple unique errors remain, they are returned as a new Errors instance.
 //
 // The deduplication process uses a map for O(n) time complexity and preserves
-// the original error instances (not just their messages).
+// the original error instances (not just their messages). The first occurrence of
+// each message is kept, in the order the errors were added. The original Errors
+// collection is not modified, and Deduplicate is safe to call on a nil receiver.
 //
 // Returns:
 //   - nil: if no errors are present
@@ -362,26 +419,64 @@ func (m *Errors) Error() string {
 //
 // Note: Deduplication is based on the string representation of errors, so
 // structurally different errors with the same message will be considered duplicates.
-func (m Errors) Deduplicate() error {
-	if len(m.Errors) == 0 {
+func (m *Errors) Deduplicate() error {
+	if m.IsEmpty() {
 		return nil
 	}
 
-	track := map[string]error{}
+	seen := make(map[string]struct{}, len(m.Errors))
+	res := make([]error, 0, len(m.Errors))
 	for i := range m.Errors {
 		e := m.Errors[i]
-		track[e.Error()] = e
+		if _, ok := seen[e.Error()]; ok {
+			continue
+		}
+		seen[e.Error()] = struct{}{}
+		res = append(res, e)
 	}
 
-	res := make([]error, len(track))
-	i := 0
-	for _, v := range track {
-		res[i] = v
-		i++
+	if len(res) == 1 {
+		return res[0]
 	}
 	return &Errors{Errors: res}
 }
 
+// ErrorOrNil returns the collection as an error, or nil if it is empty.
+//
+// Returning a *Errors directly from a function with an error result yields a
+// non-nil error even when nothing was collected. ErrorOrNil avoids this:
+//
+//	func validate(u *User) error {
+//	    var errs multierr.Errors
+//	    errs.Add(validateName(u.Name))
+//	    errs.Add(validateEmail(u.Email))
+//	    return errs.ErrorOrNil()
+//	}
+//
+// Unlike Deduplicate, the collection is returned as is, even with one error.
+func (m *Errors) ErrorOrNil() error {
+	if m.IsEmpty() {
+		return nil
+	}
+	return m
+}
+
+// Unwrap returns the collected errors for Go 1.20+ error handling.
+//
+// This method lets errors.Is and errors.As find any of the collected errors,
+// including errors wrapped within them:
+//
+//	var errs multierr.Errors
+//	errs.Add(errValidation, multierr.Wrap(os.ErrNotExist, "read config"))
+//
+//	errors.Is(&errs, os.ErrNotExist) // true
+func (m *Errors) Unwrap() []error {
+	if m == nil {
+		return nil
+	}
+	return m.Errors
+}
+
 // IsEmpty returns true if the collection contains no errors.
 //
 // This method provides a convenient way to check if any errors have been
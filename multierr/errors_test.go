@@ -5,10 +5,14 @@ package multierr_test
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"testing"
 
 	"github.com/kopexa-grc/x/multierr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Static test errors to avoid dynamic error creation
@@ -38,3 +42,70 @@ func TestMultiErr(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+var (
+	errTest2 = errors.New("2")
+	errTest3 = errors.New("3")
+)
+
+func TestErrors_Unwrap(t *testing.T) {
+	var errs multierr.Errors
+	errs.Add(errTest1, multierr.Wrap(os.ErrNotExist, "read config"))
+
+	err := fmt.Errorf("sync: %w", &errs)
+	assert.ErrorIs(t, err, errTest1)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.NotErrorIs(t, err, errTest2)
+
+	var pathErr *fs.PathError
+	errs.Add(&fs.PathError{Op: "open", Path: "config.yaml", Err: os.ErrPermission})
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, "config.yaml", pathErr.Path)
+	assert.ErrorIs(t, err, os.ErrPermission)
+
+	var nilErrs *multierr.Errors
+	assert.Nil(t, nilErrs.Unwrap())
+}
+
+func TestErrors_ErrorOrNil(t *testing.T) {
+	var errs multierr.Errors
+	assert.NoError(t, errs.ErrorOrNil())
+
+	var nilErrs *multierr.Errors
+	assert.NoError(t, nilErrs.ErrorOrNil())
+
+	errs.Add(errTest1)
+	err := errs.ErrorOrNil()
+	require.Error(t, err)
+	assert.Same(t, &errs, err)
+	assert.Equal(t, "1 error occurred:\n\t* 1\n", err.Error())
+}
+
+func TestErrors_DeduplicateOrder(t *testing.T) {
+	var errs multierr.Errors
+	errs.Add(errTest3, errTest1, errTest3, errTest2, errors.New("1"))
+
+	err := errs.Deduplicate()
+	var got *multierr.Errors
+	require.ErrorAs(t, err, &got)
+	assert.Equal(t, []error{errTest3, errTest1, errTest2}, got.Errors)
+	assert.Len(t, errs.Errors, 5, "the collection is not modified")
+
+	var single multierr.Errors
+	single.Add(errTest1, errors.New("1"))
+	assert.Same(t, errTest1, single.Deduplicate())
+
+	var nilErrs *multierr.Errors
+	assert.NoError(t, nilErrs.Deduplicate())
+}
+
+func TestErrors_Filter(t *testing.T) {
+	var errs multierr.Errors
+	errs.Add(errTest1, errTest2, errTest3)
+
+	filtered := errs.Filter(func(e error) bool { return errors.Is(e, errTest2) })
+	assert.Equal(t, []error{errTest1, errTest3}, filtered.Errors)
+
+	var nilErrs *multierr.Errors
+	assert.True(t, nilErrs.Filter(func(error) bool { return false }).IsEmpty())
+}
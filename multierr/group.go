@@ -0,0 +1,170 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrThresholdReached is the cause of the cancellation of a Group context
+// when the number of collected errors reaches the threshold set with
+// WithErrorThreshold.
+var ErrThresholdReached = errors.New("error threshold reached")
+
+// PanicError is a panic recovered in a function started by Group.Go.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the panicking goroutine, as returned by
+	// runtime/debug.Stack.
+	Stack []byte
+}
+
+// Error returns the panic value. The stack trace is not included; use the
+// Stack field to log it.
+func (p *PanicError) Error() string { return fmt.Sprintf("panic: %v", p.Value) }
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and
+// errors.As see errors passed to panic.
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// Group runs functions concurrently and collects every error they return,
+// unlike errgroup.Group, which keeps only the first.
+//
+// A Group is safe for concurrent use. The zero value runs an unlimited
+// number of functions and never cancels; use NewGroup for a context and
+// options.
+//
+// Example:
+//
+//	g, ctx := multierr.NewGroup(ctx, multierr.WithLimit(16), multierr.WithErrorThreshold(50))
+//	for _, asset := range assets {
+//	    g.Go(func() error {
+//	        return multierr.Wrap(scan(ctx, asset), "scan "+asset.ID)
+//	    })
+//	}
+//	if err := g.Wait(); err != nil {
+//	    // err is a *multierr.Errors with every failure
+//	}
+type Group struct {
+	wg        sync.WaitGroup
+	sem       chan struct{}
+	cancel    context.CancelCauseFunc
+	threshold int
+
+	mu   sync.Mutex
+	errs Errors
+}
+
+// GroupOption configures a Group created by NewGroup.
+type GroupOption func(*Group)
+
+// WithLimit limits the number of functions running at once. Go blocks
+// until a running function returns. A limit of zero or less means no limit.
+func WithLimit(n int) GroupOption {
+	return func(g *Group) {
+		if n > 0 {
+			g.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithErrorThreshold cancels the context of the Group once n errors have
+// been collected, with ErrThresholdReached as cause. Functions still
+// running should observe the context and return; errors they return are
+// still collected. A threshold of zero or less means the context is only
+// canceled by Wait.
+func WithErrorThreshold(n int) GroupOption {
+	return func(g *Group) {
+		g.threshold = n
+	}
+}
+
+// NewGroup returns a Group and a context derived from ctx. The context is
+// canceled when the error threshold is reached or when Wait returns,
+// whichever happens first.
+func NewGroup(ctx context.Context, opts ...GroupOption) (*Group, context.Context) {
+	g := &Group{}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	ctx, g.cancel = context.WithCancelCause(ctx)
+	return g, ctx
+}
+
+// Go runs f in a new goroutine and collects its error.
+//
+// If f panics, the panic is recovered and collected as a *PanicError with
+// the stack trace of the goroutine. With WithLimit, Go blocks while the
+// limit is reached; calling Go from a function of the same Group may then
+// deadlock.
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		defer func() {
+			if v := recover(); v != nil {
+				g.Add(&PanicError{Value: v, Stack: debug.Stack()})
+			}
+		}()
+
+		g.Add(f())
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// Add collects errors from outside of Go, ignoring nil errors like
+// Errors.Add. Added errors count towards the error threshold.
+func (g *Group) Add(err ...error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.errs.Add(err...)
+
+	if g.threshold > 0 && len(g.errs.Errors) >= g.threshold && g.cancel != nil {
+		g.cancel(ErrThresholdReached)
+	}
+}
+
+// Wait blocks until all functions started with Go have returned, cancels
+// the context of the Group and returns the collected errors as a
+// *Errors, or nil if there were none.
+//
+// Errors are collected in the order the functions returned.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.errs.IsEmpty() {
+		return nil
+	}
+
+	// a copy, so that later calls to Go do not modify the result
+	return &Errors{Errors: append([]error(nil), g.errs.Errors...)}
+}
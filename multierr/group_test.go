@@ -0,0 +1,111 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kopexa-grc/x/multierr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	var g multierr.Group
+	for i := range 100 {
+		g.Go(func() error {
+			if i%10 == 0 {
+				return errors.New("asset " + strconv.Itoa(i))
+			}
+			return nil
+		})
+	}
+	g.Add(nil, errTest1)
+
+	err := g.Wait()
+	var errs *multierr.Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs.Errors, 11)
+	assert.ErrorIs(t, err, errTest1)
+
+	var empty multierr.Group
+	empty.Go(func() error { return nil })
+	assert.NoError(t, empty.Wait())
+}
+
+func TestGroup_Limit(t *testing.T) {
+	g, _ := multierr.NewGroup(context.Background(), multierr.WithLimit(3))
+
+	var running, peak atomic.Int32
+	for range 20 {
+		g.Go(func() error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+
+	require.NoError(t, g.Wait())
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestGroup_Threshold(t *testing.T) {
+	g, ctx := multierr.NewGroup(context.Background(), multierr.WithErrorThreshold(2))
+
+	g.Go(func() error { return errTest1 })
+	g.Go(func() error { return errTest2 })
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	assert.ErrorIs(t, context.Cause(ctx), multierr.ErrThresholdReached)
+
+	var errs *multierr.Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs.Errors, 3, "errors after cancellation are collected")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGroup_WaitCancels(t *testing.T) {
+	g, ctx := multierr.NewGroup(context.Background())
+	g.Go(func() error { return errTest1 })
+
+	assert.Error(t, g.Wait())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
+}
+
+func TestGroup_Panic(t *testing.T) {
+	var g multierr.Group
+	g.Go(func() error { panic("boom") })
+	g.Go(func() error { panic(errTest3) })
+
+	err := g.Wait()
+	assert.ErrorIs(t, err, errTest3)
+
+	var errs *multierr.Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs.Errors, 2)
+
+	for _, err := range errs.Errors {
+		var panicErr *multierr.PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Contains(t, string(panicErr.Stack), "group_test.go")
+		assert.Contains(t, []string{"panic: boom", "panic: 3"}, panicErr.Error())
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// maxStackDepth is the maximum number of frames captured by Wrap.
+const maxStackDepth = 32
+
+// captureStacks is the default for Wrap calls without WithStack or
+// WithoutStack.
+var captureStacks atomic.Bool
+
+// SetStackCapture sets whether Wrap captures a stack trace by default.
+//
+// Stack capture is disabled by default, since it costs about a microsecond
+// per call. Individual calls override the default with WithStack and
+// WithoutStack. SetStackCapture is safe for concurrent use, but is meant to
+// be called once at startup.
+func SetStackCapture(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// WrapOption configures Wrap.
+type WrapOption func(*wrapOptions)
+
+type wrapOptions struct {
+	stack bool
+	skip  int
+}
+
+// WithStack captures the stack trace of the caller of Wrap.
+func WithStack() WrapOption {
+	return func(o *wrapOptions) {
+		o.stack = true
+	}
+}
+
+// WithoutStack disables stack capture for a call to Wrap, regardless of
+// SetStackCapture.
+func WithoutStack() WrapOption {
+	return func(o *wrapOptions) {
+		o.stack = false
+	}
+}
+
+// WithCallerSkip skips n additional frames when capturing the stack trace,
+// for helpers that call Wrap on behalf of their caller.
+func WithCallerSkip(n int) WrapOption {
+	return func(o *wrapOptions) {
+		o.skip = n
+	}
+}
+
+// stack is the program counters of a captured stack trace.
+type stack []uintptr
+
+// callers captures the stack of the caller skip frames above the caller of
+// callers.
+func callers(skip int) *stack {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	s := stack(pcs[:n])
+	return &s
+}
+
+// withStack is a withMessage with the stack trace of the call to Wrap.
+type withStack struct {
+	withMessage
+	stack *stack
+}
+
+// StackTrace returns the stack trace captured by Wrap in the format of
+// github.com/pkg/errors, which github.com/cockroachdb/errors uses to print
+// and report stack traces of foreign errors.
+func (w withStack) StackTrace() pkgerrors.StackTrace {
+	st := make(pkgerrors.StackTrace, len(*w.stack))
+	for i, pc := range *w.stack {
+		st[i] = pkgerrors.Frame(pc)
+	}
+	return st
+}
+
+// Format implements fmt.Formatter, see withMessage.Format.
+func (w withStack) Format(s fmt.State, verb rune) { formatError(s, verb, w) }
+
+// Format implements fmt.Formatter.
+//
+// The verbs %s and %v print the error message and %q prints it quoted. The
+// verb %+v prints the message followed by every error of the chain with the
+// stack traces captured by Wrap, github.com/pkg/errors or
+// github.com/cockroachdb/errors:
+//
+//	read config: open app.yaml: no such file or directory
+//	(1) read config
+//	    main.loadConfig
+//	        /src/app/config.go:42
+//	    main.main
+//	        /src/app/main.go:12
+//	(2) open app.yaml: no such file or directory
+func (w withMessage) Format(s fmt.State, verb rune) { formatError(s, verb, w) }
+
+func formatError(s fmt.State, verb rune, err error) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, formatChain(err))
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", err.Error())
+	default:
+		_, _ = io.WriteString(s, err.Error())
+	}
+}
+
+// formatChain returns the message of err followed by each error of its
+// chain with its stack trace.
+func formatChain(err error) string {
+	var b strings.Builder
+	b.WriteString(err.Error())
+
+	for i := 1; err != nil; i++ {
+		b.WriteString("\n(" + strconv.Itoa(i) + ") ")
+
+		switch w := err.(type) {
+		case withMessage:
+			b.WriteString(w.msg)
+		case withStack:
+			b.WriteString(w.msg)
+		default:
+			b.WriteString(err.Error())
+		}
+
+		for _, f := range framesOf(err) {
+			b.WriteString("\n    " + f.Function + "\n        " + f.File + ":" + strconv.Itoa(f.Line))
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return b.String()
+}
+
+// stackTracer is implemented by errors carrying a stack trace from Wrap,
+// github.com/pkg/errors or github.com/cockroachdb/errors.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// framesOf returns the frames of the stack trace of err itself, without
+// unwrapping it.
+func framesOf(err error) []runtime.Frame {
+	st, ok := err.(stackTracer)
+	if !ok {
+		return nil
+	}
+
+	trace := st.StackTrace()
+	if len(trace) == 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, len(trace))
+	for i, f := range trace {
+		pcs[i] = uintptr(f)
+	}
+
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, more := iter.Next()
+		frames = append(frames, f)
+		if !more {
+			return frames
+		}
+	}
+}
+
+// StackFrames returns the frames of the stack trace closest to the origin of
+// err: of all errors in the chain of err carrying a stack trace, captured by
+// Wrap, github.com/pkg/errors or github.com/cockroachdb/errors, the
+// innermost one is used. The first frame is the caller that created the
+// error.
+//
+// StackFrames follows Unwrap() error only, so errors collected in an Errors
+// are not inspected. It returns nil if no error in the chain has a stack
+// trace.
+//
+// Example:
+//
+//	multierr.SetStackCapture(true)
+//
+//	err := multierr.Wrap(os.ErrNotExist, "read config")
+//	for _, f := range multierr.StackFrames(err) {
+//	    log.Printf("%s (%s:%d)", f.Function, f.File, f.Line)
+//	}
+func StackFrames(err error) []runtime.Frame {
+	var frames []runtime.Frame
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f := framesOf(err); f != nil {
+			frames = f
+		}
+	}
+	return frames
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr_test
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	crerrors "github.com/cockroachdb/errors"
+	"github.com/kopexa-grc/x/multierr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// line returns the line of its caller.
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+func TestWrap_WithoutStack(t *testing.T) {
+	err := multierr.Wrap(os.ErrNotExist, "read config")
+	assert.Nil(t, multierr.StackFrames(err))
+
+	assert.Equal(t, "read config: file does not exist", fmt.Sprintf("%v", err))
+	assert.Equal(t, "read config: file does not exist", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"read config: file does not exist"`, fmt.Sprintf("%q", err))
+	assert.Equal(t, "read config: file does not exist\n(1) read config\n(2) file does not exist",
+		fmt.Sprintf("%+v", err))
+}
+
+func TestWrap_WithStack(t *testing.T) {
+	err, wrapLine := multierr.Wrap(os.ErrNotExist, "read config", multierr.WithStack()), line()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, "read config: file does not exist", err.Error())
+
+	frames := multierr.StackFrames(fmt.Errorf("load: %w", err))
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/kopexa-grc/x/multierr_test.TestWrap_WithStack", frames[0].Function)
+	assert.Equal(t, wrapLine, frames[0].Line)
+
+	formatted := fmt.Sprintf("%+v", multierr.Wrap(err, "start"))
+	assert.True(t, strings.HasPrefix(formatted, "start: read config: file does not exist\n(1) start\n(2) read config\n"))
+	assert.Contains(t, formatted, fmt.Sprintf("multierr_test.TestWrap_WithStack\n        %s:%d", frames[0].File, wrapLine))
+	assert.True(t, strings.HasSuffix(formatted, "\n(3) file does not exist"))
+}
+
+func TestSetStackCapture(t *testing.T) {
+	multierr.SetStackCapture(true)
+	t.Cleanup(func() { multierr.SetStackCapture(false) })
+
+	assert.NotNil(t, multierr.StackFrames(multierr.Wrap(os.ErrNotExist, "read config")))
+	assert.Nil(t, multierr.StackFrames(multierr.Wrap(os.ErrNotExist, "read config", multierr.WithoutStack())))
+}
+
+func wrapForCaller(err error) error {
+	return multierr.Wrap(err, "helper", multierr.WithStack(), multierr.WithCallerSkip(1))
+}
+
+func TestWrap_CallerSkip(t *testing.T) {
+	err, wrapLine := wrapForCaller(os.ErrNotExist), line()
+
+	frames := multierr.StackFrames(err)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/kopexa-grc/x/multierr_test.TestWrap_CallerSkip", frames[0].Function)
+	assert.Equal(t, wrapLine, frames[0].Line)
+}
+
+func TestWrap_CockroachInterop(t *testing.T) {
+	t.Run("stack of a cockroach error", func(t *testing.T) {
+		cause, causeLine := crerrors.New("connection refused"), line()
+		err := multierr.Wrap(cause, "query", multierr.WithStack())
+
+		frames := multierr.StackFrames(err)
+		require.NotEmpty(t, frames)
+		assert.Equal(t, causeLine, frames[0].Line, "the innermost stack is used")
+		assert.True(t, crerrors.Is(err, cause))
+	})
+
+	t.Run("stack reported by cockroach", func(t *testing.T) {
+		err, wrapLine := multierr.Wrap(os.ErrNotExist, "read config", multierr.WithStack()), line()
+
+		st := crerrors.GetReportableStackTrace(err)
+		require.NotNil(t, st)
+		require.NotEmpty(t, st.Frames)
+		// sentry frames are ordered from the outermost call
+		assert.Equal(t, wrapLine, st.Frames[len(st.Frames)-1].Lineno)
+
+		formatted := fmt.Sprintf("%+v", crerrors.Wrap(err, "start"))
+		assert.Contains(t, formatted, "start: read config: file does not exist")
+		assert.Contains(t, formatted, fmt.Sprintf("stack_test.go:%d", wrapLine))
+	})
+}
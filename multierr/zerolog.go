@@ -0,0 +1,154 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names of errors logged with zerolog.
+const (
+	LogFieldMessage = "message"
+	LogFieldType    = "type"
+	LogFieldCode    = "code"
+	LogFieldCauses  = "causes"
+	LogFieldErrors  = "errors"
+)
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler, so that
+// zerolog's Err and AnErr log the collection as an object instead of a
+// multi-line string:
+//
+//	{"message": "2 errors occurred", "errors": [
+//	    {"message": "scan AC-1: timeout", "type": "multierr.withMessage", "causes": [...]},
+//	    {"message": "control not found", "type": "*multierr.Error", "code": "not_found"}
+//	]}
+//
+// Each collected error is logged like a wrapped error, see Wrap.
+func (m *Errors) MarshalZerologObject(e *zerolog.Event) {
+	e.Str(LogFieldMessage, m.summary())
+	e.Array(LogFieldErrors, m)
+}
+
+// MarshalZerologArray implements zerolog.LogArrayMarshaler, logging each
+// collected error as an object.
+func (m *Errors) MarshalZerologArray(a *zerolog.Array) {
+	if m == nil {
+		return
+	}
+	for _, err := range m.Errors {
+		a.Object(errorObject{err})
+	}
+}
+
+// summary returns the first line of Error without the colon.
+func (m *Errors) summary() string {
+	n := 0
+	if m != nil {
+		n = len(m.Errors)
+	}
+	if n == 1 {
+		return "1 error occurred"
+	}
+	return strconv.Itoa(n) + " errors occurred"
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler. Errors
+// returned by Wrap are logged as an object with:
+//
+//   - message: the error message, with collections summarized on one line
+//   - type: the Go type of the error
+//   - code: the code of the first Error in the chain, if any
+//   - causes: the message and type of each error in the chain
+//   - errors: the errors of the first Errors in the chain, if any
+//   - stack: the innermost stack trace in the chain, if any, under
+//     zerolog.ErrorStackFieldName
+func (w withMessage) MarshalZerologObject(e *zerolog.Event) { marshalError(e, w) }
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler, see
+// withMessage.MarshalZerologObject.
+func (w withStack) MarshalZerologObject(e *zerolog.Event) { marshalError(e, w) }
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler, see
+// withMessage.MarshalZerologObject.
+func (e *Error) MarshalZerologObject(ev *zerolog.Event) { marshalError(ev, e) }
+
+// errorObject logs any error like marshalError.
+type errorObject struct{ err error }
+
+func (o errorObject) MarshalZerologObject(e *zerolog.Event) { marshalError(e, o.err) }
+
+// marshalError logs err as an object. Collections are logged with their
+// own fields, see Errors.MarshalZerologObject.
+func marshalError(e *zerolog.Event, err error) {
+	if errs, ok := err.(*Errors); ok {
+		errs.MarshalZerologObject(e)
+		return
+	}
+
+	e.Str(LogFieldMessage, logMessage(err))
+	e.Str(LogFieldType, fmt.Sprintf("%T", err))
+
+	var (
+		code   Code
+		errs   *Errors
+		causes = zerolog.Arr()
+	)
+	for cur, first := err, true; cur != nil && errs == nil; cur, first = errors.Unwrap(cur), false {
+		switch x := cur.(type) {
+		case *Error:
+			if code == "" {
+				code = x.Code
+			}
+		case *Errors:
+			errs = x
+		}
+
+		if !first {
+			causes.Dict(zerolog.Dict().
+				Str(LogFieldMessage, logMessage(cur)).
+				Str(LogFieldType, fmt.Sprintf("%T", cur)))
+		}
+	}
+
+	if code != "" {
+		e.Str(LogFieldCode, string(code))
+	}
+	if errors.Unwrap(err) != nil {
+		e.Array(LogFieldCauses, causes)
+	}
+	if errs != nil {
+		e.Array(LogFieldErrors, errs)
+	}
+
+	if frames := StackFrames(err); frames != nil {
+		stack := make([]string, len(frames))
+		for i, f := range frames {
+			stack[i] = f.Function + " " + f.File + ":" + strconv.Itoa(f.Line)
+		}
+		e.Strs(zerolog.ErrorStackFieldName, stack)
+	}
+}
+
+// logMessage returns the message of err on one line: collections wrapped by
+// Wrap and WithCode are summarized instead of listing their errors.
+func logMessage(err error) string {
+	switch x := err.(type) {
+	case *Errors:
+		return x.summary()
+	case withMessage:
+		return x.msg + ": " + logMessage(x.cause)
+	case withStack:
+		return x.msg + ": " + logMessage(x.cause)
+	case *Error:
+		if x.cause != nil {
+			return (&Error{Code: x.Code, Message: x.Message}).Error() + ": " + logMessage(x.cause)
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package multierr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/kopexa-grc/x/multierr"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logError logs err with zerolog's Err and returns the decoded error field.
+func logError(t *testing.T, err error) any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+	log.Error().Err(err).Msg("failed")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	return entry[zerolog.ErrorFieldName]
+}
+
+func TestErrors_Zerolog(t *testing.T) {
+	var errs multierr.Errors
+	errs.Add(
+		multierr.Wrap(os.ErrNotExist, "scan AC-1"),
+		multierr.New(multierr.CodeNotFound, "control not found"),
+		errors.New("plain"),
+	)
+
+	assert.Equal(t, map[string]any{
+		"message": "3 errors occurred",
+		"errors": []any{
+			map[string]any{
+				"message": "scan AC-1: file does not exist",
+				"type":    "multierr.withMessage",
+				"causes": []any{
+					map[string]any{"message": "file does not exist", "type": "*errors.errorString"},
+				},
+			},
+			map[string]any{
+				"message": "not_found: control not found",
+				"type":    "*multierr.Error",
+				"code":    "not_found",
+			},
+			map[string]any{
+				"message": "plain",
+				"type":    "*errors.errorString",
+			},
+		},
+	}, logError(t, &errs))
+
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+	log.Info().Array("errs", &errs).Send()
+	assert.Contains(t, buf.String(), `"errs":[{"message":"scan AC-1: file does not exist"`)
+}
+
+func TestWrap_Zerolog(t *testing.T) {
+	var errs multierr.Errors
+	errs.Add(errTest1, errTest2)
+
+	err := multierr.Wrap(
+		multierr.WithCode(&errs, multierr.CodeInvalidArgument, "invalid control"),
+		"update control",
+	)
+
+	assert.Equal(t, map[string]any{
+		"message": "update control: invalid_argument: invalid control: 2 errors occurred",
+		"type":    "multierr.withMessage",
+		"code":    "invalid_argument",
+		"causes": []any{
+			map[string]any{"message": "invalid_argument: invalid control: 2 errors occurred", "type": "*multierr.Error"},
+			map[string]any{"message": "2 errors occurred", "type": "*multierr.Errors"},
+		},
+		"errors": []any{
+			map[string]any{"message": "1", "type": "*errors.errorString"},
+			map[string]any{"message": "2", "type": "*errors.errorString"},
+		},
+	}, logError(t, err))
+}
+
+func TestWrap_ZerologStack(t *testing.T) {
+	err := multierr.Wrap(os.ErrNotExist, "read config", multierr.WithStack())
+
+	logged, ok := logError(t, err).(map[string]any)
+	require.True(t, ok)
+
+	stack, ok := logged[zerolog.ErrorStackFieldName].([]any)
+	require.True(t, ok)
+	require.NotEmpty(t, stack)
+	assert.Contains(t, stack[0], "multierr_test.TestWrap_ZerologStack")
+	assert.Contains(t, stack[0], "zerolog_test.go:")
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package session provides server-side HTTP sessions backed by Redis.
+//
+// # Overview
+//
+// The session package ties the cookie and cache packages together. Clients only
+// ever receive an opaque, randomly generated session ID in a cookie created with
+// cookie.New; all session data is kept server-side in a Store. The default
+// Store implementation persists sessions in Redis using a client created by
+// cache.New.
+//
+// # Features
+//
+//   - Opaque 256-bit session IDs generated with crypto/rand
+//   - Sliding expiration: every access extends the session lifetime
+//   - Session ID regeneration on privilege changes (e.g. login)
+//   - Revocation of all sessions belonging to a user
+//   - Echo middleware that loads the session into the request context
+//
+// # Usage
+//
+// Setting up the manager and middleware:
+//
+//	client := cache.New(cache.Config{Address: "localhost:6379"})
+//
+//	manager := session.NewManager(session.NewRedisStore(client), session.Config{
+//		Cookie: cookie.Config{
+//			Name:     "session",
+//			Path:     "/",
+//			Secure:   true,
+//			HTTPOnly: true,
+//			SameSite: http.SameSiteLaxMode,
+//		},
+//		IdleTimeout: 30 * time.Minute,
+//	})
+//
+//	e := echo.New()
+//	e.Use(manager.Middleware())
+//
+// Accessing the session in a handler:
+//
+//	func login(c echo.Context) error {
+//		s, _ := session.FromContext(c.Request().Context())
+//
+//		// Issue a fresh session ID to prevent session fixation.
+//		if err := manager.Regenerate(c.Request().Context(), s); err != nil {
+//			return err
+//		}
+//
+//		s.SetUserID(user.ID)
+//		s.Set("role", user.Role)
+//
+//		return c.NoContent(http.StatusNoContent)
+//	}
+//
+// Modified sessions are persisted and the cookie is written automatically by
+// the middleware right before the response headers are sent.
+//
+// # Security Considerations
+//
+//   - Always call Regenerate after authentication or privilege changes
+//   - Use RevokeUser after password changes to terminate other sessions
+//   - Configure the session cookie with Secure, HTTPOnly and SameSite
+package session
@@ -0,0 +1,151 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package session
+
+import (
+	"context"
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// contextKey is the key under which the session is stored in a context.Context.
+type contextKey struct{}
+
+// WithSession returns a new context.Context carrying the given session.
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the session stored in ctx by the middleware.
+//
+// Example:
+//
+//	func handler(c echo.Context) error {
+//		s, ok := session.FromContext(c.Request().Context())
+//		if !ok {
+//			return echo.ErrUnauthorized
+//		}
+//		return c.JSON(http.StatusOK, s.Values)
+//	}
+func FromContext(ctx context.Context) (*Session, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	s, ok := ctx.Value(contextKey{}).(*Session)
+
+	return s, ok && s != nil
+}
+
+// MiddlewareConfig defines optional configuration for the session middleware.
+type MiddlewareConfig struct {
+	// Skipper defines a function to skip the middleware for certain requests.
+	// If nil, middleware.DefaultSkipper is used (never skips).
+	Skipper middleware.Skipper
+}
+
+// Middleware returns an Echo middleware that loads the session referenced by
+// the request cookie into the request context.
+//
+// If the request carries no session cookie, or the referenced session has
+// expired, a new unsaved session is created. New sessions are only persisted
+// once they are modified. Existing sessions are re-saved on modification and
+// their cookie is refreshed to keep the browser expiration in sync with the
+// sliding server-side expiration.
+//
+// Sessions are committed right before the response headers are written.
+//
+// Example:
+//
+//	e := echo.New()
+//	e.Use(manager.Middleware())
+func (m *Manager) Middleware() echo.MiddlewareFunc {
+	return m.MiddlewareWithConfig(MiddlewareConfig{})
+}
+
+// MiddlewareWithConfig returns the session middleware with the given configuration.
+// See Middleware for details.
+func (m *Manager) MiddlewareWithConfig(config MiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			ctx := req.Context()
+
+			s, err := m.loadFromRequest(c)
+			if err != nil {
+				return err
+			}
+
+			existing := !s.IsNew()
+
+			c.SetRequest(req.WithContext(WithSession(ctx, s)))
+
+			var (
+				committed bool
+				commitErr error
+			)
+			commit := func() {
+				if committed {
+					return
+				}
+				committed = true
+				commitErr = m.commitFromRequest(ctx, c, s, existing)
+			}
+			c.Response().Before(commit)
+
+			if err := next(c); err != nil {
+				return err
+			}
+
+			// handlers that do not write a response never trigger Before
+			if !c.Response().Committed {
+				commit()
+			}
+
+			return commitErr
+		}
+	}
+}
+
+// loadFromRequest loads the session referenced by the request cookie or
+// creates a new one.
+func (m *Manager) loadFromRequest(c echo.Context) (*Session, error) {
+	if ck, err := c.Cookie(m.config.Cookie.Name); err == nil && ck.Value != "" {
+		s, err := m.Load(c.Request().Context(), ck.Value)
+		if err == nil {
+			return s, nil
+		}
+
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	return m.New()
+}
+
+// commitFromRequest persists modified sessions and refreshes the cookie of
+// unmodified existing sessions. Destroyed and untouched new sessions are
+// left alone.
+func (m *Manager) commitFromRequest(ctx context.Context, c echo.Context, s *Session, existing bool) error {
+	if s.IsModified() {
+		return m.Commit(ctx, c.Response(), s)
+	}
+
+	if existing && !s.IsNew() {
+		c.SetCookie(m.cookie(s.ID))
+	}
+
+	return nil
+}
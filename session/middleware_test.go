@@ -0,0 +1,103 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kopexa-grc/x/cookie"
+	"github.com/kopexa-grc/x/session"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	store := newMemoryStore()
+	m := session.NewManager(store, session.Config{
+		Cookie: cookie.Config{Name: "sid", Path: "/", HTTPOnly: true},
+	})
+
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/noop", func(c echo.Context) error {
+		s, ok := session.FromContext(c.Request().Context())
+		require.True(t, ok)
+		return c.String(http.StatusOK, s.UserID)
+	})
+	e.POST("/login", func(c echo.Context) error {
+		s, _ := session.FromContext(c.Request().Context())
+		if err := m.Regenerate(c.Request().Context(), s); err != nil {
+			return err
+		}
+		s.SetUserID("alice")
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.POST("/logout", func(c echo.Context) error {
+		s, _ := session.FromContext(c.Request().Context())
+		return m.Destroy(c.Request().Context(), c.Response(), s)
+	})
+
+	do := func(method, path string, ck *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if ck != nil {
+			req.AddCookie(ck)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("does not persist untouched new sessions", func(t *testing.T) {
+		rec := do(http.MethodGet, "/noop", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Result().Cookies())
+		assert.Empty(t, store.sessions)
+	})
+
+	t.Run("ignores unknown session ids", func(t *testing.T) {
+		rec := do(http.MethodGet, "/noop", &http.Cookie{Name: "sid", Value: "forged"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	t.Run("login, access and logout", func(t *testing.T) {
+		rec := do(http.MethodPost, "/login", nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		sid := cookies[0]
+		assert.True(t, store.has(sid.Value))
+
+		rec = do(http.MethodGet, "/noop", sid)
+		assert.Equal(t, "alice", rec.Body.String())
+		require.Len(t, rec.Result().Cookies(), 1, "cookie should be refreshed")
+		assert.Equal(t, sid.Value, rec.Result().Cookies()[0].Value)
+
+		rec = do(http.MethodPost, "/login", sid)
+		require.Len(t, rec.Result().Cookies(), 1)
+		regenerated := rec.Result().Cookies()[0]
+		assert.NotEqual(t, sid.Value, regenerated.Value)
+		assert.False(t, store.has(sid.Value), "old session should be removed")
+
+		rec = do(http.MethodPost, "/logout", regenerated)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, rec.Result().Cookies(), 1)
+		assert.Empty(t, rec.Result().Cookies()[0].Value)
+		assert.False(t, store.has(regenerated.Value))
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := session.FromContext(t.Context())
+	assert.False(t, ok)
+
+	s := &session.Session{ID: "abc"}
+	got, ok := session.FromContext(session.WithSession(t.Context(), s))
+	assert.True(t, ok)
+	assert.Same(t, s, got)
+}
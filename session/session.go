@@ -0,0 +1,307 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kopexa-grc/x/cookie"
+)
+
+const (
+	// DefaultCookieName is the cookie name used when Config.Cookie.Name is empty.
+	DefaultCookieName = "session_id"
+
+	// DefaultIdleTimeout is the sliding expiration used when Config.IdleTimeout is zero.
+	DefaultIdleTimeout = 30 * time.Minute
+
+	// idBytes is the number of random bytes in a session ID (256 bits).
+	idBytes = 32
+)
+
+var (
+	// ErrNotFound indicates that no session exists for the given ID.
+	ErrNotFound = errors.New("session not found")
+
+	// ErrInvalidSession indicates that a nil or ID-less session was passed.
+	ErrInvalidSession = errors.New("invalid session")
+)
+
+// Session holds server-side data associated with a single client.
+//
+// Only the ID is ever sent to the client. Values are serialized as JSON by the
+// RedisStore, so numbers read back from a persisted session are float64 values
+// and nested structs become map[string]any.
+type Session struct {
+	// ID is the opaque session identifier stored in the cookie.
+	ID string `json:"id"`
+
+	// UserID is the identifier of the authenticated user, if any.
+	// It is used to revoke all sessions of a user.
+	UserID string `json:"user_id,omitempty"`
+
+	// Values contains arbitrary session data.
+	Values map[string]any `json:"values,omitempty"`
+
+	// CreatedAt is the time the session was first created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// LastAccessedAt is the time the session was last loaded or saved.
+	// Load does not persist it, so the stored value is the time of the
+	// last save.
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+
+	isNew    bool
+	modified bool
+}
+
+// Get returns the value stored under key.
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.Values[key]
+	return v, ok
+}
+
+// Set stores value under key and marks the session as modified.
+func (s *Session) Set(key string, value any) {
+	if s.Values == nil {
+		s.Values = make(map[string]any)
+	}
+	s.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored under key and marks the session as modified.
+func (s *Session) Delete(key string) {
+	if _, ok := s.Values[key]; !ok {
+		return
+	}
+	delete(s.Values, key)
+	s.modified = true
+}
+
+// SetUserID associates the session with a user and marks it as modified.
+func (s *Session) SetUserID(userID string) {
+	s.UserID = userID
+	s.modified = true
+}
+
+// IsNew reports whether the session has not been persisted yet.
+func (s *Session) IsNew() bool { return s.isNew }
+
+// IsModified reports whether the session has unsaved changes.
+func (s *Session) IsModified() bool { return s.modified }
+
+// Config configures a Manager.
+type Config struct {
+	// Cookie configures the session cookie. A positive Cookie.MaxAge is
+	// replaced by IdleTimeout so that browser and server expiration slide
+	// together. Leave MaxAge at 0 to issue a browser-session cookie.
+	// Cookie.Name defaults to DefaultCookieName.
+	Cookie cookie.Config
+
+	// IdleTimeout is the sliding expiration of a session. Every access
+	// extends the lifetime by IdleTimeout.
+	// Defaults to DefaultIdleTimeout.
+	IdleTimeout time.Duration
+}
+
+// Manager issues, loads and persists sessions.
+//
+// A Manager is safe for concurrent use if its Store is.
+type Manager struct {
+	store  Store
+	config Config
+	now    func() time.Time
+}
+
+// NewManager creates a new Manager using the given store and configuration.
+//
+// Example:
+//
+//	client := cache.New(cache.Config{Address: "localhost:6379"})
+//	manager := session.NewManager(session.NewRedisStore(client), session.Config{
+//		Cookie:      cookie.Config{Path: "/", Secure: true, HTTPOnly: true},
+//		IdleTimeout: time.Hour,
+//	})
+func NewManager(store Store, config Config) *Manager {
+	if config.Cookie.Name == "" {
+		config.Cookie.Name = DefaultCookieName
+	}
+
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+
+	return &Manager{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// CookieName returns the name of the session cookie.
+func (m *Manager) CookieName() string {
+	return m.config.Cookie.Name
+}
+
+// New creates a new, unsaved session with a fresh ID.
+func (m *Manager) New() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+
+	return &Session{
+		ID:             id,
+		Values:         make(map[string]any),
+		CreatedAt:      now,
+		LastAccessedAt: now,
+		isNew:          true,
+	}, nil
+}
+
+// Load retrieves the session with the given ID from the store and extends
+// its lifetime by the configured idle timeout. The stored session is not
+// rewritten, so concurrent requests do not overwrite each other's changes;
+// use Save or Commit to persist modifications.
+//
+// Returns ErrNotFound if the session does not exist or has expired.
+func (m *Manager) Load(ctx context.Context, id string) (*Session, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+
+	s, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.isNew = false
+	s.modified = false
+
+	if err := m.store.Touch(ctx, s, m.config.IdleTimeout); err != nil {
+		return nil, fmt.Errorf("failed to extend session: %w", err)
+	}
+
+	s.LastAccessedAt = m.now()
+
+	return s, nil
+}
+
+// Save persists the session and clears its modified flag.
+func (m *Manager) Save(ctx context.Context, s *Session) error {
+	if s == nil || s.ID == "" {
+		return ErrInvalidSession
+	}
+
+	s.LastAccessedAt = m.now()
+	if err := m.store.Save(ctx, s, m.config.IdleTimeout); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	s.isNew = false
+	s.modified = false
+
+	return nil
+}
+
+// Commit persists the session and writes the session cookie to w.
+func (m *Manager) Commit(ctx context.Context, w http.ResponseWriter, s *Session) error {
+	if err := m.Save(ctx, s); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, m.cookie(s.ID))
+
+	return nil
+}
+
+// Regenerate replaces the session ID while keeping all session data.
+//
+// The old session is removed from the store and the session is marked as
+// modified so that the new ID is persisted and sent to the client. Call
+// Regenerate after login or any other privilege change to prevent session
+// fixation attacks.
+func (m *Manager) Regenerate(ctx context.Context, s *Session) error {
+	if s == nil || s.ID == "" {
+		return ErrInvalidSession
+	}
+
+	if !s.isNew {
+		if err := m.store.Delete(ctx, s.ID); err != nil {
+			return fmt.Errorf("failed to delete old session: %w", err)
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	s.ID = id
+	s.isNew = true
+	s.modified = true
+
+	return nil
+}
+
+// Destroy removes the session from the store and expires the session cookie.
+func (m *Manager) Destroy(ctx context.Context, w http.ResponseWriter, s *Session) error {
+	if s == nil || s.ID == "" {
+		return ErrInvalidSession
+	}
+
+	if err := m.store.Delete(ctx, s.ID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	// prevent the middleware from persisting the session again
+	s.isNew = true
+	s.modified = false
+
+	http.SetCookie(w, cookie.Expire("", &m.config.Cookie))
+
+	return nil
+}
+
+// RevokeUser removes all sessions associated with the given user ID.
+func (m *Manager) RevokeUser(ctx context.Context, userID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	if err := m.store.DeleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// cookie builds the session cookie for the given ID.
+func (m *Manager) cookie(id string) *http.Cookie {
+	config := m.config.Cookie
+	if config.MaxAge > 0 {
+		config.MaxAge = int(m.config.IdleTimeout / time.Second)
+	}
+
+	return cookie.New("", id, &config)
+}
+
+// newID generates a new random, URL-safe session ID.
+func newID() (string, error) {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package session_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kopexa-grc/x/cookie"
+	"github.com/kopexa-grc/x/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory session.Store used for testing.
+type memoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]session.Session
+	ttls     map[string]time.Duration
+	saves    int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		sessions: make(map[string]session.Session),
+		ttls:     make(map[string]time.Duration),
+	}
+}
+
+func (m *memoryStore) Get(_ context.Context, id string) (*session.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, session.ErrNotFound
+	}
+
+	return &s, nil
+}
+
+func (m *memoryStore) Save(_ context.Context, s *session.Session, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[s.ID] = *s
+	m.ttls[s.ID] = ttl
+	m.saves++
+
+	return nil
+}
+
+func (m *memoryStore) Touch(_ context.Context, s *session.Session, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[s.ID]; !ok {
+		return session.ErrNotFound
+	}
+
+	m.ttls[s.ID] = ttl
+
+	return nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+
+	return nil
+}
+
+func (m *memoryStore) DeleteUser(_ context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStore) has(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.sessions[id]
+
+	return ok
+}
+
+func TestManagerNew(t *testing.T) {
+	m := session.NewManager(newMemoryStore(), session.Config{})
+
+	s1, err := m.New()
+	require.NoError(t, err)
+	s2, err := m.New()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, s1.ID, s2.ID)
+	assert.Len(t, s1.ID, 43, "32 random bytes, base64url encoded")
+	assert.True(t, s1.IsNew())
+	assert.False(t, s1.IsModified())
+	assert.Equal(t, session.DefaultCookieName, m.CookieName())
+}
+
+func TestManagerLoad(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	m := session.NewManager(store, session.Config{IdleTimeout: time.Hour})
+
+	t.Run("returns ErrNotFound for unknown id", func(t *testing.T) {
+		_, err := m.Load(ctx, "unknown")
+		assert.ErrorIs(t, err, session.ErrNotFound)
+
+		_, err = m.Load(ctx, "")
+		assert.ErrorIs(t, err, session.ErrNotFound)
+	})
+
+	t.Run("extends lifetime on access", func(t *testing.T) {
+		s, err := m.New()
+		require.NoError(t, err)
+		s.Set("key", "value")
+		require.NoError(t, m.Save(ctx, s))
+		assert.False(t, s.IsNew())
+		assert.False(t, s.IsModified())
+
+		store.ttls[s.ID] = 0
+		saves := store.saves
+
+		loaded, err := m.Load(ctx, s.ID)
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, store.ttls[s.ID])
+		assert.Equal(t, saves, store.saves, "loading must not rewrite the session")
+
+		v, ok := loaded.Get("key")
+		assert.True(t, ok)
+		assert.Equal(t, "value", v)
+		assert.False(t, loaded.IsNew())
+	})
+}
+
+func TestManagerRegenerate(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	m := session.NewManager(store, session.Config{})
+
+	s, err := m.New()
+	require.NoError(t, err)
+	s.Set("cart", "42")
+	require.NoError(t, m.Save(ctx, s))
+
+	loaded, err := m.Load(ctx, s.ID)
+	require.NoError(t, err)
+
+	oldID := loaded.ID
+	require.NoError(t, m.Regenerate(ctx, loaded))
+
+	assert.NotEqual(t, oldID, loaded.ID)
+	assert.False(t, store.has(oldID), "old session should be deleted")
+	assert.True(t, loaded.IsModified())
+
+	v, _ := loaded.Get("cart")
+	assert.Equal(t, "42", v, "values should be preserved")
+
+	assert.ErrorIs(t, m.Regenerate(ctx, nil), session.ErrInvalidSession)
+}
+
+func TestManagerRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	m := session.NewManager(store, session.Config{})
+
+	var ids []string
+	for _, user := range []string{"alice", "alice", "bob"} {
+		s, err := m.New()
+		require.NoError(t, err)
+		s.SetUserID(user)
+		require.NoError(t, m.Save(ctx, s))
+		ids = append(ids, s.ID)
+	}
+
+	require.NoError(t, m.RevokeUser(ctx, "alice"))
+
+	assert.False(t, store.has(ids[0]))
+	assert.False(t, store.has(ids[1]))
+	assert.True(t, store.has(ids[2]))
+}
+
+func TestManagerCommitAndDestroy(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	m := session.NewManager(store, session.Config{
+		Cookie: cookie.Config{
+			Name:     "sid",
+			Path:     "/",
+			MaxAge:   60,
+			Secure:   true,
+			HTTPOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+		IdleTimeout: 10 * time.Minute,
+	})
+
+	s, err := m.New()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, m.Commit(ctx, rec, s))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "sid", cookies[0].Name)
+	assert.Equal(t, s.ID, cookies[0].Value)
+	assert.Equal(t, 600, cookies[0].MaxAge, "MaxAge should follow the idle timeout")
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, store.has(s.ID))
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, m.Destroy(ctx, rec, s))
+
+	cookies = rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "sid", cookies[0].Name)
+	assert.Empty(t, cookies[0].Value)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+	assert.False(t, store.has(s.ID))
+}
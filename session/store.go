@@ -0,0 +1,206 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKeyPrefix is the Redis key prefix used by RedisStore.
+const DefaultKeyPrefix = "session:"
+
+// Store persists sessions.
+//
+// Implementations must return ErrNotFound from Get when no session exists
+// for the given ID.
+type Store interface {
+	// Get returns the session with the given ID.
+	Get(ctx context.Context, id string) (*Session, error)
+
+	// Save stores the session and expires it after ttl.
+	Save(ctx context.Context, s *Session, ttl time.Duration) error
+
+	// Touch expires the stored session after ttl without rewriting it.
+	// Touch returns ErrNotFound when the session no longer exists.
+	Touch(ctx context.Context, s *Session, ttl time.Duration) error
+
+	// Delete removes the session with the given ID.
+	// Deleting a non-existent session is not an error.
+	Delete(ctx context.Context, id string) error
+
+	// DeleteUser removes all sessions associated with the given user ID.
+	DeleteUser(ctx context.Context, userID string) error
+}
+
+// RedisStore is a Store backed by Redis.
+//
+// Sessions are stored as JSON under "<prefix><id>". For every user a set
+// "<prefix>user:<userID>" tracks the IDs of the user's sessions so that they
+// can be revoked at once.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// RedisStoreOption configures a RedisStore.
+type RedisStoreOption func(*RedisStore)
+
+// WithKeyPrefix sets the Redis key prefix. Defaults to DefaultKeyPrefix.
+func WithKeyPrefix(prefix string) RedisStoreOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
+
+// NewRedisStore creates a new RedisStore using a client created by cache.New.
+//
+// Example:
+//
+//	client := cache.New(cache.Config{Address: "localhost:6379"})
+//	store := session.NewRedisStore(client, session.WithKeyPrefix("app:session:"))
+func NewRedisStore(client *redis.Client, opts ...RedisStoreOption) *RedisStore {
+	s := &RedisStore{
+		client: client,
+		prefix: DefaultKeyPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Get returns the session with the given ID or ErrNotFound.
+func (r *RedisStore) Get(ctx context.Context, id string) (*Session, error) {
+	data, err := r.client.Get(ctx, r.sessionKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSession(data)
+}
+
+// Save stores the session and expires it after ttl. If the session belongs to
+// a user, the user's session index is updated and its lifetime extended.
+func (r *RedisStore) Save(ctx context.Context, s *Session, ttl time.Duration) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, r.sessionKey(s.ID), data, ttl)
+
+		if s.UserID != "" {
+			userKey := r.userKey(s.UserID)
+			pipe.SAdd(ctx, userKey, s.ID)
+			pipe.Expire(ctx, userKey, ttl)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// Touch expires the session after ttl. If the session belongs to a user, the
+// lifetime of the user's session index is extended as well.
+func (r *RedisStore) Touch(ctx context.Context, s *Session, ttl time.Duration) error {
+	var expire *redis.BoolCmd
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		expire = pipe.Expire(ctx, r.sessionKey(s.ID), ttl)
+
+		if s.UserID != "" {
+			pipe.Expire(ctx, r.userKey(s.UserID), ttl)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !expire.Val() {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the session with the given ID and removes it from the
+// session index of its user.
+func (r *RedisStore) Delete(ctx context.Context, id string) error {
+	key := r.sessionKey(id)
+
+	data, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// an undecodable session is still deleted, it just cannot be
+	// removed from a user index
+	s, _ := decodeSession(data)
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+
+		if s != nil && s.UserID != "" {
+			pipe.SRem(ctx, r.userKey(s.UserID), id)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// DeleteUser removes all sessions associated with the given user ID.
+func (r *RedisStore) DeleteUser(ctx context.Context, userID string) error {
+	userKey := r.userKey(userID)
+
+	ids, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, r.sessionKey(id))
+	}
+	keys = append(keys, userKey)
+
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func decodeSession(data []byte) (*Session, error) {
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *RedisStore) sessionKey(id string) string {
+	return r.prefix + id
+}
+
+func (r *RedisStore) userKey(userID string) string {
+	return r.prefix + "user:" + userID
+}
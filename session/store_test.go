@@ -0,0 +1,149 @@
+// Copyright (c) Kopexa GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kopexa-grc/x/session"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisStore(t *testing.T, opts ...session.RedisStoreOption) (*session.RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return session.NewRedisStore(client, opts...), mr
+}
+
+func TestRedisStore_SaveAndGet(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newRedisStore(t)
+
+	_, err := store.Get(ctx, "unknown")
+	require.ErrorIs(t, err, session.ErrNotFound)
+
+	s := &session.Session{ID: "abc", UserID: "alice", Values: map[string]any{"cart": "42"}}
+	require.NoError(t, store.Save(ctx, s, time.Hour))
+
+	got, err := store.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.UserID)
+	assert.Equal(t, map[string]any{"cart": "42"}, got.Values)
+
+	// key layout
+	raw, err := mr.Get("session:abc")
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(raw)))
+	members, err := mr.Members("session:user:alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, members)
+
+	assert.Equal(t, time.Hour, mr.TTL("session:abc"))
+	assert.Equal(t, time.Hour, mr.TTL("session:user:alice"))
+
+	mr.FastForward(time.Hour)
+	_, err = store.Get(ctx, "abc")
+	assert.ErrorIs(t, err, session.ErrNotFound)
+}
+
+func TestRedisStore_KeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newRedisStore(t, session.WithKeyPrefix("app:"))
+
+	require.NoError(t, store.Save(ctx, &session.Session{ID: "abc", UserID: "alice"}, time.Minute))
+
+	assert.True(t, mr.Exists("app:abc"))
+	assert.True(t, mr.Exists("app:user:alice"))
+	assert.False(t, mr.Exists("session:abc"))
+}
+
+func TestRedisStore_Touch(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newRedisStore(t)
+
+	s := &session.Session{ID: "abc", UserID: "alice", Values: map[string]any{"cart": "42"}}
+	require.NoError(t, store.Save(ctx, s, time.Minute))
+
+	// a stale copy must not overwrite the stored values
+	stale := &session.Session{ID: "abc", UserID: "alice"}
+	require.NoError(t, store.Touch(ctx, stale, time.Hour))
+
+	assert.Equal(t, time.Hour, mr.TTL("session:abc"))
+	assert.Equal(t, time.Hour, mr.TTL("session:user:alice"))
+
+	got, err := store.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"cart": "42"}, got.Values)
+
+	err = store.Touch(ctx, &session.Session{ID: "unknown"}, time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound)
+}
+
+func TestRedisStore_Delete(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newRedisStore(t)
+
+	require.NoError(t, store.Save(ctx, &session.Session{ID: "a", UserID: "alice"}, time.Hour))
+	require.NoError(t, store.Save(ctx, &session.Session{ID: "b", UserID: "alice"}, time.Hour))
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	require.NoError(t, store.Delete(ctx, "unknown"))
+
+	assert.False(t, mr.Exists("session:a"))
+	assert.True(t, mr.Exists("session:b"))
+	members, err := mr.Members("session:user:alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, members)
+
+	require.NoError(t, store.Delete(ctx, "b"))
+	assert.False(t, mr.Exists("session:user:alice"), "empty user index is removed")
+}
+
+func TestRedisStore_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newRedisStore(t)
+
+	require.NoError(t, store.Save(ctx, &session.Session{ID: "a", UserID: "alice"}, time.Hour))
+	require.NoError(t, store.Save(ctx, &session.Session{ID: "b", UserID: "alice"}, time.Hour))
+	require.NoError(t, store.Save(ctx, &session.Session{ID: "c", UserID: "bob"}, time.Hour))
+
+	require.NoError(t, store.DeleteUser(ctx, "alice"))
+	require.NoError(t, store.DeleteUser(ctx, "unknown"))
+
+	assert.False(t, mr.Exists("session:a"))
+	assert.False(t, mr.Exists("session:b"))
+	assert.False(t, mr.Exists("session:user:alice"))
+	assert.True(t, mr.Exists("session:c"))
+	assert.True(t, mr.Exists("session:user:bob"))
+}
+
+func TestManagerRegenerate_RedisStore(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newRedisStore(t)
+	m := session.NewManager(store, session.Config{})
+
+	s, err := m.New()
+	require.NoError(t, err)
+	s.SetUserID("alice")
+	require.NoError(t, m.Save(ctx, s))
+
+	oldID := s.ID
+	require.NoError(t, m.Regenerate(ctx, s))
+	require.NoError(t, m.Save(ctx, s))
+
+	members, err := mr.Members("session:user:alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{s.ID}, members)
+	assert.False(t, mr.Exists("session:"+oldID))
+}